@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCrashMaxSize  = 1 << 20
+	defaultCrashMaxFiles = 10
+)
+
+// CrashReport defines where and how crash reports are written when
+// Fatal, Fatalf, Trace run or a panic is re-panicked by Recover with Repanic().
+type CrashReport struct {
+	// Dir of report files, default to the directory of log file or os.TempDir().
+	Dir string
+
+	// MaxSize caps bytes of each report, default to 1MB.
+	MaxSize int
+
+	// MaxFiles is the number of reports retained, default to 10.
+	MaxFiles int
+
+	// Env is the allowlist of environment variables included in reports.
+	Env []string
+}
+
+// SetCrashReport enables crash reports of Logger, a nil value disables it.
+// NOTE: Crash reports are shared by all loggers created with l.New().
+func (l *Logger) SetCrashReport(cr *CrashReport) {
+	l.tree.crash.Store(cr)
+}
+
+// crashed writes a crash report if it's enabled, errors are ignored
+// for the process is going to die.
+func (l *Logger) crashed(msg string, stack []byte) {
+	cr := l.tree.crash.Load()
+	if cr == nil {
+		return
+	}
+
+	l.mux.RLock()
+	path := l.path
+	l.mux.RUnlock()
+
+	tags := l.Tags()
+
+	_, _ = cr.write(path, msg, tags, stack)
+}
+
+func (cr *CrashReport) write(path, msg string, tags []string, stack []byte) (string, error) {
+	dir, prefix := cr.Dir, "logger"
+	if path != "" {
+		if dir == "" {
+			dir = filepath.Dir(path)
+		}
+
+		prefix = filepath.Base(path)
+	}
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	maxSize := cr.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultCrashMaxSize
+	}
+
+	now := time.Now()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "message: %s\n", msg)
+	fmt.Fprintf(&buf, "time: %s\n", now.Format(time.RFC3339Nano))
+	fmt.Fprintf(&buf, "pid: %d\n", os.Getpid())
+	fmt.Fprintf(&buf, "tags: %s\n", strings.Join(tags, ", "))
+
+	buf.WriteString("\n== build ==\n")
+	if info, ok := debug.ReadBuildInfo(); ok {
+		buf.WriteString(info.String())
+	} else {
+		buf.WriteString("unavailable\n")
+	}
+
+	buf.WriteString("\n== environment ==\n")
+	for _, key := range cr.Env {
+		if value, ok := os.LookupEnv(key); ok {
+			fmt.Fprintf(&buf, "%s=%s\n", key, value)
+		}
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	buf.WriteString("\n== memstats ==\n")
+	fmt.Fprintf(&buf, "Alloc: %d\n", mem.Alloc)
+	fmt.Fprintf(&buf, "TotalAlloc: %d\n", mem.TotalAlloc)
+	fmt.Fprintf(&buf, "Sys: %d\n", mem.Sys)
+	fmt.Fprintf(&buf, "HeapAlloc: %d\n", mem.HeapAlloc)
+	fmt.Fprintf(&buf, "HeapInuse: %d\n", mem.HeapInuse)
+	fmt.Fprintf(&buf, "HeapObjects: %d\n", mem.HeapObjects)
+	fmt.Fprintf(&buf, "StackInuse: %d\n", mem.StackInuse)
+	fmt.Fprintf(&buf, "NumGC: %d\n", mem.NumGC)
+	fmt.Fprintf(&buf, "NumGoroutine: %d\n", runtime.NumGoroutine())
+
+	if len(stack) > 0 {
+		buf.WriteString("\n== stack ==\n")
+		buf.Write(stack)
+		buf.WriteByte('\n')
+	}
+
+	// all goroutines dump comes last, it's the first to be truncated
+	buf.WriteString("\n== goroutines ==\n")
+	dump := make([]byte, maxSize)
+	n := runtime.Stack(dump, true)
+	buf.Write(dump[:n])
+
+	data := buf.Bytes()
+	if len(data) > maxSize {
+		marker := fmt.Sprintf("\n...(truncated, %d bytes)\n", len(data))
+		if len(marker) < maxSize {
+			data = append(data[:maxSize-len(marker)], marker...)
+		} else {
+			data = data[:maxSize]
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	filename := filepath.Join(dir, fmt.Sprintf("%s.crash.%s.%d", prefix, now.Format("20060102T150405.000000000"), os.Getpid()))
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", err
+	}
+
+	cr.rotate(dir, prefix)
+
+	return filename, nil
+}
+
+// rotate removes the oldest reports exceeded MaxFiles.
+func (cr *CrashReport) rotate(dir, prefix string) {
+	maxFiles := cr.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultCrashMaxFiles
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, prefix+".crash.*"))
+	if err != nil || len(files) <= maxFiles {
+		return
+	}
+
+	// timestamp of filename is sortable
+	sort.Strings(files)
+
+	for _, file := range files[:len(files)-maxFiles] {
+		os.Remove(file)
+	}
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_CrashReport(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LOGGER_CRASH_TESTING", "yes")
+
+	logger, err := New(filepath.Join(dir, "app.log"))
+	assert.Nil(t, err)
+	logger.SetTags("crash")
+	logger.SetCrashReport(&CrashReport{
+		Env: []string{"LOGGER_CRASH_TESTING"},
+	})
+
+	// panics recovered are not crashes
+	assert.NotPanics(t, func() {
+		defer Recover(logger)
+
+		panic("recovered")
+	})
+
+	files, _ := filepath.Glob(filepath.Join(dir, "app.log.crash.*"))
+	assert.Empty(t, files)
+
+	assert.Panics(t, func() {
+		defer Recover(logger, Repanic())
+
+		panic("boom")
+	})
+
+	files, _ = filepath.Glob(filepath.Join(dir, "app.log.crash.*"))
+	assert.Equal(t, 1, len(files))
+
+	data, err := os.ReadFile(files[0])
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "message: panic: boom")
+	assert.Contains(t, string(data), "tags: crash")
+	assert.Contains(t, string(data), "LOGGER_CRASH_TESTING=yes")
+	assert.Contains(t, string(data), "== memstats ==")
+	assert.Contains(t, string(data), "== goroutines ==")
+}
+
+func Test_CrashReport_Retention(t *testing.T) {
+	dir := t.TempDir()
+
+	cr := &CrashReport{
+		Dir:      dir,
+		MaxSize:  256,
+		MaxFiles: 2,
+	}
+
+	for i := 0; i < 5; i++ {
+		_, err := cr.write("", "crashed", nil, nil)
+		assert.Nil(t, err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "logger.crash.*"))
+	assert.Equal(t, 2, len(files))
+
+	data, err := os.ReadFile(files[0])
+	assert.Nil(t, err)
+	assert.Equal(t, 256, len(data))
+	assert.True(t, strings.HasSuffix(string(data), "bytes)\n"))
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SetDedup collapses consecutive logs with the same level, tags, message and fields
+// within window, and writes a summary of "last message repeated N times" when the
+// streak ends or the window elapses. A window of 0 disables it.
+// NOTE: The dedup is shared by all loggers created with l.New().
+func (l *Logger) SetDedup(window time.Duration) {
+	var d *dedup
+	if window > 0 {
+		d = &dedup{
+			window: window,
+		}
+	}
+
+	if prev := l.tree.dedup.Swap(d); prev != nil {
+		prev.flush()
+	}
+}
+
+// repeated is the summary of a collapsed streak.
+type repeated struct {
+	logger *Logger
+	record Record
+	count  int
+}
+
+func (rp repeated) write() {
+	if rp.count == 0 {
+		return
+	}
+
+	r := rp.record
+	r.Time = time.Now()
+	r.Message = fmt.Sprintf("last message repeated %d times", rp.count)
+	r.stacks = nil
+	if len(r.Fields) > 0 {
+		r.Fields = []slog.Attr{slog.Int("repeated", rp.count)}
+	}
+
+	_ = rp.logger.write(&r)
+}
+
+type dedup struct {
+	mux sync.Mutex
+
+	window time.Duration
+	key    string
+	last   time.Time
+	streak repeated
+	timer  *time.Timer
+}
+
+// pass returns whether the record should be written, summary of the previous
+// streak is written before if it ends.
+func (d *dedup) pass(l *Logger, r *Record) bool {
+	var fields string
+	if len(r.Fields) > 0 {
+		fields = r.attrs().String()
+	}
+
+	key := strings.Join([]string{r.Level.String(), strings.Join(r.Tags, ", "), fields, r.Message}, "\x00")
+	now := time.Now()
+
+	d.mux.Lock()
+	if key == d.key && now.Sub(d.last) <= d.window {
+		d.last = now
+		d.streak.count++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window, d.flush)
+		}
+		d.mux.Unlock()
+
+		return false
+	}
+
+	streak := d.streak
+
+	d.key = key
+	d.last = now
+	d.streak = repeated{
+		logger: l,
+		record: r.clone(),
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.mux.Unlock()
+
+	streak.write()
+
+	return true
+}
+
+// flush writes summary of current streak when the window elapses.
+func (d *dedup) flush() {
+	d.mux.Lock()
+	streak := d.streak
+
+	d.streak.count = 0
+	d.timer = nil
+	d.mux.Unlock()
+
+	streak.write()
+}
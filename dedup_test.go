@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_Dedup(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetDedup(time.Minute)
+
+	for i := 0; i < 5; i++ {
+		logger.Warn("disk is almost full")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "disk is almost full"))
+
+	logger.Info("disk cleaned")
+
+	output := buf.String()
+	assert.Contains(t, output, "[WARN] - ")
+	assert.Contains(t, output, "last message repeated 4 times")
+	assert.True(t, strings.Index(output, "repeated 4 times") < strings.Index(output, "disk cleaned"))
+}
+
+func Test_Logger_DedupWithTimer(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetDedup(50 * time.Millisecond)
+
+	log := logger.NewJsonLogger().Str("key", "value")
+	for i := 0; i < 3; i++ {
+		log.Error("connection refused")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "connection refused"))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Contains(t, buf.String(), `{"repeated":2} last message repeated 2 times`)
+}
+
+func Test_Logger_DedupWithDifferentTags(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetDedup(time.Minute)
+
+	logger.Info("hello")
+	logger.New("child").Info("hello")
+	assert.Equal(t, 2, strings.Count(buf.String(), "hello"))
+	assert.NotContains(t, buf.String(), "repeated")
+}
@@ -0,0 +1,700 @@
+package logger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// BadValue prefixes values failed to encode, e.g. "!BADVALUE: unsupported type chan int".
+	BadValue = "!BADVALUE"
+
+	maxEncodeDepth = 64
+)
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// jsonEncoder encodes fields as JSON, values which can not be encoded are
+// written as strings of BadValue instead of failing the whole record.
+type jsonEncoder struct {
+	buf *bytes.Buffer
+
+	// seen holds pointers being encoded for detecting cycles.
+	seen map[uintptr]struct{}
+}
+
+// encodeAttrs writes attrs as a JSON object with keys sorted, the last one wins
+// for duplicated keys.
+func (enc *jsonEncoder) encodeAttrs(attrs []slog.Attr, depth int) {
+	var stack [16]int
+
+	order := stack[:0]
+	for i := range attrs {
+		order = append(order, i)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return strings.Compare(attrs[a].Key, attrs[b].Key)
+	})
+
+	enc.buf.WriteByte('{')
+	for i, n := 0, len(order); i < n; i++ {
+		attr := attrs[order[i]]
+		if i+1 < n && attrs[order[i+1]].Key == attr.Key {
+			continue
+		}
+
+		if enc.buf.Bytes()[enc.buf.Len()-1] != '{' {
+			enc.buf.WriteByte(',')
+		}
+
+		writeJSONString(enc.buf, attr.Key)
+		enc.buf.WriteByte(':')
+		enc.encodeValue(attr.Value, depth)
+	}
+	enc.buf.WriteByte('}')
+}
+
+func (enc *jsonEncoder) encodeValue(v slog.Value, depth int) {
+	switch v.Kind() {
+	case slog.KindString:
+		writeJSONString(enc.buf, v.String())
+
+	case slog.KindBool:
+		enc.buf.Write(strconv.AppendBool(enc.buf.AvailableBuffer(), v.Bool()))
+
+	case slog.KindInt64:
+		enc.buf.Write(strconv.AppendInt(enc.buf.AvailableBuffer(), v.Int64(), 10))
+
+	case slog.KindUint64:
+		enc.buf.Write(strconv.AppendUint(enc.buf.AvailableBuffer(), v.Uint64(), 10))
+
+	case slog.KindFloat64:
+		enc.encodeFloat(v.Float64())
+
+	case slog.KindDuration:
+		enc.buf.WriteByte('"')
+		enc.buf.Write(appendDuration(enc.buf.AvailableBuffer(), v.Duration()))
+		enc.buf.WriteByte('"')
+
+	case slog.KindTime:
+		enc.buf.WriteByte('"')
+		enc.buf.Write(v.Time().AppendFormat(enc.buf.AvailableBuffer(), time.RFC3339Nano))
+		enc.buf.WriteByte('"')
+
+	case slog.KindGroup:
+		if depth >= maxEncodeDepth {
+			enc.badValue("max depth exceeded")
+			return
+		}
+
+		enc.encodeAttrs(v.Group(), depth+1)
+
+	case slog.KindLogValuer:
+		enc.encodeValue(v.Resolve(), depth)
+
+	default:
+		enc.encodeAny(v.Any(), depth)
+	}
+}
+
+func (enc *jsonEncoder) encodeAny(value any, depth int) {
+	if value == nil {
+		enc.buf.WriteString("null")
+		return
+	}
+
+	start := enc.buf.Len()
+	defer func() {
+		if err := recover(); err != nil {
+			enc.buf.Truncate(start)
+			enc.badValue(fmt.Sprintf("%T panic: %v", value, err))
+		}
+	}()
+
+	switch v := value.(type) {
+	case string:
+		writeJSONString(enc.buf, v)
+		return
+
+	case []byte:
+		enc.encodeBytes(v)
+		return
+
+	case float64:
+		enc.encodeFloat(v)
+		return
+
+	case float32:
+		enc.encodeFloat(float64(v))
+		return
+
+	case json.Marshaler:
+		enc.encodeMarshaler(v)
+		return
+
+	case error:
+		writeJSONString(enc.buf, v.Error())
+		return
+
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			enc.badValue(err.Error())
+			return
+		}
+
+		writeJSONString(enc.buf, string(text))
+		return
+
+	case fmt.Stringer:
+		writeJSONString(enc.buf, v.String())
+		return
+	}
+
+	enc.encodeReflect(reflect.ValueOf(value), depth)
+}
+
+func (enc *jsonEncoder) encodeReflect(rv reflect.Value, depth int) {
+	if depth >= maxEncodeDepth {
+		enc.badValue("max depth exceeded")
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		enc.buf.WriteString(strconv.FormatBool(rv.Bool()))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc.buf.WriteString(strconv.FormatInt(rv.Int(), 10))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		enc.buf.WriteString(strconv.FormatUint(rv.Uint(), 10))
+
+	case reflect.Float32, reflect.Float64:
+		enc.encodeFloat(rv.Float())
+
+	case reflect.String:
+		writeJSONString(enc.buf, rv.String())
+
+	case reflect.Interface:
+		if rv.IsNil() {
+			enc.buf.WriteString("null")
+			return
+		}
+
+		enc.encodeElem(rv.Elem(), depth)
+
+	case reflect.Pointer:
+		if rv.IsNil() {
+			enc.buf.WriteString("null")
+			return
+		}
+
+		if !enc.enter(rv) {
+			return
+		}
+		enc.encodeElem(rv.Elem(), depth)
+		enc.leave(rv)
+
+	case reflect.Slice:
+		if rv.IsNil() {
+			enc.buf.WriteString("null")
+			return
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			enc.encodeBytes(rv.Bytes())
+			return
+		}
+
+		if !enc.enter(rv) {
+			return
+		}
+		enc.encodeArray(rv, depth)
+		enc.leave(rv)
+
+	case reflect.Array:
+		enc.encodeArray(rv, depth)
+
+	case reflect.Map:
+		if rv.IsNil() {
+			enc.buf.WriteString("null")
+			return
+		}
+
+		if !enc.enter(rv) {
+			return
+		}
+		enc.encodeMap(rv, depth)
+		enc.leave(rv)
+
+	case reflect.Struct:
+		enc.buf.WriteByte('{')
+		enc.encodeFields(rv, depth, false)
+		enc.buf.WriteByte('}')
+
+	default:
+		enc.badValue("unsupported type " + rv.Type().String())
+	}
+}
+
+// encodeElem encodes nested value, it respects marshalers of the value.
+func (enc *jsonEncoder) encodeElem(rv reflect.Value, depth int) {
+	if rv.CanInterface() {
+		enc.encodeAny(rv.Interface(), depth+1)
+		return
+	}
+
+	enc.encodeReflect(rv, depth+1)
+}
+
+func (enc *jsonEncoder) encodeArray(rv reflect.Value, depth int) {
+	enc.buf.WriteByte('[')
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			enc.buf.WriteByte(',')
+		}
+
+		enc.encodeElem(rv.Index(i), depth)
+	}
+	enc.buf.WriteByte(']')
+}
+
+func (enc *jsonEncoder) encodeMap(rv reflect.Value, depth int) {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, rv.Len())
+
+	iter := rv.MapRange()
+	for iter.Next() {
+		entries = append(entries, entry{
+			key:   mapKey(iter.Key()),
+			value: iter.Value(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	enc.buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			enc.buf.WriteByte(',')
+		}
+
+		writeJSONString(enc.buf, e.key)
+		enc.buf.WriteByte(':')
+		enc.encodeElem(e.value, depth)
+	}
+	enc.buf.WriteByte('}')
+}
+
+// encodeFields writes exported fields of struct with names of json tags, fields
+// of embedded structs are promoted. It returns whether any field is written.
+func (enc *jsonEncoder) encodeFields(rv reflect.Value, depth int, more bool) bool {
+	if depth >= maxEncodeDepth {
+		return more
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := rv.Field(i)
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && !ft.Implements(jsonMarshalerType) && !ft.Implements(textMarshalerType) {
+				more = enc.encodeFields(fv, depth+1, more) || more
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(fv) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if more {
+			enc.buf.WriteByte(',')
+		}
+		more = true
+
+		writeJSONString(enc.buf, name)
+		enc.buf.WriteByte(':')
+		enc.encodeElem(fv, depth)
+	}
+
+	return more
+}
+
+func (enc *jsonEncoder) encodeMarshaler(m json.Marshaler) {
+	if rv := reflect.ValueOf(m); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		enc.buf.WriteString("null")
+		return
+	}
+
+	b, err := m.MarshalJSON()
+	if err != nil {
+		enc.badValue(err.Error())
+		return
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, b); err != nil {
+		enc.badValue(fmt.Sprintf("%T returns invalid JSON", m))
+		return
+	}
+
+	enc.buf.Write(compacted.Bytes())
+}
+
+// encodeBytes writes valid UTF-8 bytes as string, others are encoded with base64.
+func (enc *jsonEncoder) encodeBytes(b []byte) {
+	if utf8.Valid(b) {
+		writeJSONString(enc.buf, string(b))
+		return
+	}
+
+	writeJSONString(enc.buf, base64.StdEncoding.EncodeToString(b))
+}
+
+// encodeFloat writes NaN and Inf as strings which are not supported by JSON.
+func (enc *jsonEncoder) encodeFloat(f float64) {
+	switch {
+	case math.IsNaN(f):
+		enc.buf.WriteString(`"NaN"`)
+
+	case math.IsInf(f, 1):
+		enc.buf.WriteString(`"+Inf"`)
+
+	case math.IsInf(f, -1):
+		enc.buf.WriteString(`"-Inf"`)
+
+	default:
+		enc.buf.Write(strconv.AppendFloat(enc.buf.AvailableBuffer(), f, 'g', -1, 64))
+	}
+}
+
+func (enc *jsonEncoder) badValue(reason string) {
+	writeJSONString(enc.buf, BadValue+": "+reason)
+}
+
+// enter returns false and writes BadValue if the pointer of rv is being encoded.
+func (enc *jsonEncoder) enter(rv reflect.Value) bool {
+	ptr := rv.Pointer()
+	if _, ok := enc.seen[ptr]; ok {
+		enc.badValue("cycle of " + rv.Type().String())
+		return false
+	}
+
+	if enc.seen == nil {
+		enc.seen = make(map[uintptr]struct{})
+	}
+	enc.seen[ptr] = struct{}{}
+
+	return true
+}
+
+func (enc *jsonEncoder) leave(rv reflect.Value) {
+	delete(enc.seen, rv.Pointer())
+}
+
+func mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+
+	if key.CanInterface() {
+		if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+			if text, err := tm.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+
+	return fmt.Sprint(key)
+}
+
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return rv.IsZero()
+	}
+
+	return false
+}
+
+// writeJSONString writes s as a JSON string, invalid UTF-8 is replaced with U+FFFD.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString("\ufffd")
+
+			i += size
+			start = i
+			continue
+		}
+
+		// U+2028 and U+2029 break JavaScript parsers
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xf])
+
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+// writeTextAttrs writes attrs as key=value pairs separated by ", ", keys are
+// quoted the same as values.
+func writeTextAttrs(buf *bytes.Buffer, attrs []slog.Attr) {
+	for i, attr := range attrs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+
+		writeTextString(buf, attr.Key)
+		buf.WriteByte('=')
+		writeTextValue(buf, attr.Value)
+	}
+}
+
+func writeTextValue(buf *bytes.Buffer, v slog.Value) {
+	switch v.Kind() {
+	case slog.KindString:
+		writeTextString(buf, v.String())
+
+	case slog.KindBool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), v.Bool()))
+
+	case slog.KindInt64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), v.Int64(), 10))
+
+	case slog.KindUint64:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), v.Uint64(), 10))
+
+	case slog.KindFloat64:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), v.Float64(), 'g', -1, 64))
+
+	case slog.KindDuration:
+		buf.Write(appendDuration(buf.AvailableBuffer(), v.Duration()))
+
+	case slog.KindTime:
+		// the layout of time.Time.String() contains spaces always
+		buf.WriteByte('"')
+		buf.Write(v.Time().AppendFormat(buf.AvailableBuffer(), "2006-01-02 15:04:05.999999999 -0700 MST"))
+		buf.WriteByte('"')
+
+	default:
+		writeTextString(buf, v.String())
+	}
+}
+
+// writeTextString writes s quoted if it's required, see needsQuote.
+func writeTextString(buf *bytes.Buffer, s string) {
+	if !needsQuote(s) {
+		buf.WriteString(s)
+		return
+	}
+
+	buf.Write(strconv.AppendQuote(buf.AvailableBuffer(), s))
+}
+
+// appendDuration appends d formatted the same as time.Duration.String().
+func appendDuration(b []byte, d time.Duration) []byte {
+	// largest time is 2540400h10m10.000000000s
+	var buf [32]byte
+
+	w := len(buf)
+
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+
+	if u < uint64(time.Second) {
+		// special case: if duration is smaller than a second,
+		// use smaller units, like 1.2ms
+		var prec int
+		w--
+		buf[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			return append(b, "0s"...)
+		case u < uint64(time.Microsecond):
+			prec = 0
+			buf[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			// U+00B5 'µ' micro sign == 0xC2 0xB5
+			w--
+			copy(buf[w:], "µ")
+		default:
+			prec = 6
+			buf[w] = 'm'
+		}
+		w, u = fmtFrac(buf[:w], u, prec)
+		w = fmtInt(buf[:w], u)
+	} else {
+		w--
+		buf[w] = 's'
+
+		w, u = fmtFrac(buf[:w], u, 9)
+
+		// u is now integer seconds
+		w = fmtInt(buf[:w], u%60)
+		u /= 60
+
+		// u is now integer minutes
+		if u > 0 {
+			w--
+			buf[w] = 'm'
+			w = fmtInt(buf[:w], u%60)
+			u /= 60
+
+			// u is now integer hours
+			if u > 0 {
+				w--
+				buf[w] = 'h'
+				w = fmtInt(buf[:w], u)
+			}
+		}
+	}
+
+	if neg {
+		w--
+		buf[w] = '-'
+	}
+
+	return append(b, buf[w:]...)
+}
+
+// fmtFrac formats the fraction of v/10**prec (e.g., ".12345") into the
+// tail of buf, omitting trailing zeros. It omits the decimal
+// point too when the fraction is 0. It returns the index where the
+// output bytes begin and the value v/10**prec.
+func fmtFrac(buf []byte, v uint64, prec int) (nw int, nv uint64) {
+	w := len(buf)
+	print := false
+	for i := 0; i < prec; i++ {
+		digit := v % 10
+		print = print || digit != 0
+		if print {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if print {
+		w--
+		buf[w] = '.'
+	}
+
+	return w, v
+}
+
+// fmtInt formats v into the tail of buf.
+// It returns the index where the output begins.
+func fmtInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+	} else {
+		for v > 0 {
+			w--
+			buf[w] = byte(v%10) + '0'
+			v /= 10
+		}
+	}
+
+	return w
+}
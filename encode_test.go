@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+type badMarshaler struct{}
+
+func (badMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("broken")
+}
+
+type invalidMarshaler struct{}
+
+func (invalidMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"unterminated"`), nil
+}
+
+type panicStringer struct{}
+
+func (panicStringer) String() string {
+	panic("boom")
+}
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y,omitempty"`
+}
+
+func jsonOf(fields ...Attr) string {
+	as := &attrs{
+		fields: appendAttrs(nil, fields...),
+	}
+
+	return as.JSONString()
+}
+
+func Test_Logger_JSONEncodeAny(t *testing.T) {
+	type embedded struct {
+		ID string `json:"id"`
+	}
+
+	type payload struct {
+		embedded
+		Name    string            `json:"name"`
+		Secret  string            `json:"-"`
+		Point   point             `json:"point"`
+		Labels  map[string]string `json:"labels,omitempty"`
+		private int
+	}
+
+	assert.Equal(t, `{"error":"failed","ip":"127.0.0.1","nil":null,"time":"2025-01-02T03:04:05Z","timeout":"2s"}`, jsonOf(
+		Any("error", errors.New("failed")),
+		Any("ip", netip.MustParseAddr("127.0.0.1")),
+		Any("nil", nil),
+		Any("time", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Any("timeout", 2*time.Second),
+	))
+
+	assert.Equal(t, `{"payload":{"id":"p1","name":"test","point":{"x":1}}}`, jsonOf(
+		Any("payload", &payload{embedded: embedded{ID: "p1"}, Name: "test", Secret: "hidden", Point: point{X: 1}}),
+	))
+
+	assert.Equal(t, `{"list":[1,"two",null],"map":{"1":true,"2":false}}`, jsonOf(
+		Any("list", []any{1, "two", nil}),
+		Any("map", map[int]bool{2: false, 1: true}),
+	))
+
+	assert.Equal(t, `{"binary":"/wA=","bytes":"text"}`, jsonOf(
+		Any("bytes", []byte("text")),
+		Any("binary", []byte{0xff, 0x00}),
+	))
+
+	assert.Equal(t, `{"inf":"+Inf","nan":"NaN","ninf":"-Inf","pi":3.14}`, jsonOf(
+		Any("nan", math.NaN()),
+		Any("inf", math.Inf(1)),
+		Any("ninf", float32(math.Inf(-1))),
+		Any("pi", 3.14),
+	))
+}
+
+func Test_Logger_JSONEncodeBadValue(t *testing.T) {
+	type node struct {
+		Name string `json:"name"`
+		Next *node  `json:"next"`
+	}
+
+	n := &node{Name: "cycle"}
+	n.Next = n
+
+	s := jsonOf(
+		Any("chan", make(chan int)),
+		Any("func", func() {}),
+		Any("broken", badMarshaler{}),
+		Any("invalid", invalidMarshaler{}),
+		Any("panic", panicStringer{}),
+		Any("cycle", n),
+		String("key", "value"),
+	)
+
+	var fields map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(s), &fields))
+
+	assert.Equal(t, "!BADVALUE: unsupported type chan int", fields["chan"])
+	assert.Equal(t, "!BADVALUE: unsupported type func()", fields["func"])
+	assert.Equal(t, "!BADVALUE: broken", fields["broken"])
+	assert.Equal(t, "!BADVALUE: logger.invalidMarshaler returns invalid JSON", fields["invalid"])
+	assert.Equal(t, "!BADVALUE: logger.panicStringer panic: boom", fields["panic"])
+	assert.Equal(t, map[string]any{
+		"name": "cycle",
+		"next": "!BADVALUE: cycle of *logger.node",
+	}, fields["cycle"])
+	assert.Equal(t, "value", fields["key"])
+}
+
+func Test_Logger_JSONEncodeShared(t *testing.T) {
+	p := &point{X: 1, Y: 2}
+
+	// shared but not cyclic pointers are encoded
+	s := jsonOf(Any("points", []*point{p, p}))
+	assert.Equal(t, `{"points":[{"x":1,"y":2},{"x":1,"y":2}]}`, s)
+	assert.False(t, strings.Contains(s, BadValue))
+}
+
+func Test_Logger_EncodeTypedFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	logger.NewTextLogger(Int("status", 200)).
+		Float64("ratio", 0.5).
+		Duration("latency", 1500*time.Microsecond).
+		Time("at", at).
+		Info("served")
+	assert.Contains(t, buf.String(), `status=200, ratio=0.5, latency=1.5ms, at="2025-01-02 03:04:05 +0000 UTC", msg=served`)
+
+	buf.Reset()
+	logger.NewJsonLogger(Int("status", 200)).
+		Float64("ratio", 0.5).
+		Duration("latency", 1500*time.Microsecond).
+		Time("at", at).
+		Info("served")
+	assert.Contains(t, buf.String(), `{"at":"2025-01-02T03:04:05Z","latency":"1.5ms","ratio":0.5,"status":200} served`)
+}
+
+func Test_Logger_EncodeDuplicatedKeys(t *testing.T) {
+	assert.Equal(t, `{"a":3,"b":2}`, jsonOf(Int("a", 1), Int("b", 2), Int("a", 3)))
+}
+
+func Test_Logger_EncodeJSONString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"plain",
+		"quote\" backslash\\ slash/",
+		"ctrl\x00\x01\x1f\t\r\n",
+		"unicode 日本語   ",
+		"invalid \xff\xfe utf8",
+	} {
+		var buf bytes.Buffer
+		writeJSONString(&buf, s)
+
+		expected, _ := json.Marshal(s)
+		assert.Equal(t, string(expected), buf.String())
+	}
+}
+
+func Test_Logger_EncodeDuration(t *testing.T) {
+	for _, d := range []time.Duration{
+		0, 1, 999, time.Microsecond, 1500 * time.Microsecond, time.Second,
+		90 * time.Minute, -2*time.Hour - 3*time.Second, 1<<63 - 1, -1 << 63,
+	} {
+		assert.Equal(t, d.String(), string(appendDuration(nil, d)))
+	}
+}
+
+func Test_Logger_EncodeWithoutAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items randomly with race detector")
+	}
+
+	ctx := context.Background()
+
+	text, _ := New("stdout")
+	text.SetOutput(io.Discard)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		text.LogAttrs(ctx, slog.LevelInfo, "served", slog.String("method", "GET"), slog.Int("status", 200), slog.Bool("cached", true))
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	json, _ := New("stdout")
+	json.SetSinks(&Sink{Name: "json", Writer: io.Discard, Format: JSONFormat})
+
+	allocs = testing.AllocsPerRun(100, func() {
+		json.LogAttrs(ctx, slog.LevelInfo, "served", slog.String("method", "GET"), slog.Int("status", 200), slog.Duration("latency", time.Millisecond))
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	// disabled level
+	text.SetLevel(Lwarn)
+
+	allocs = testing.AllocsPerRun(100, func() {
+		text.LogAttrs(ctx, slog.LevelDebug, "served", slog.String("method", "GET"))
+		text.NewTextLogger().Debugf("served %s", "GET")
+	})
+	assert.Equal(t, float64(1), allocs)
+}
+
+func Test_Logger_EncodeWithPooledRecords(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Lwarn)
+	logger.SetFlightRecorder(10)
+
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "kept", slog.String("key", "first"))
+	for i := 0; i < 5; i++ {
+		logger.LogAttrs(context.Background(), slog.LevelWarn, "written", slog.String("key", "other"))
+	}
+
+	assert.Nil(t, logger.DumpFlightRecorder())
+	assert.Contains(t, buf.String(), "key=first, msg=kept")
+}
+
+func benchmarkLogAttrs(b *testing.B, logger *Logger, level slog.Level) {
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.LogAttrs(ctx, level, "request served",
+			slog.String("method", "GET"),
+			slog.Int("status", 200),
+			slog.Bool("cached", true),
+			slog.Duration("latency", 1500*time.Microsecond),
+		)
+	}
+}
+
+func Benchmark_Logger_LogAttrsText(b *testing.B) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+
+	benchmarkLogAttrs(b, logger, slog.LevelInfo)
+}
+
+func Benchmark_Logger_LogAttrsJSON(b *testing.B) {
+	logger, _ := New("stdout")
+	logger.SetSinks(&Sink{Name: "json", Writer: io.Discard, Format: JSONFormat})
+
+	benchmarkLogAttrs(b, logger, slog.LevelInfo)
+}
+
+func Benchmark_Logger_LogAttrsDisabled(b *testing.B) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(Lwarn)
+
+	benchmarkLogAttrs(b, logger, slog.LevelDebug)
+}
+
+func Benchmark_Logger_StructLogger(b *testing.B) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.NewJsonLogger().
+			Str("method", "GET").
+			Int("status", 200).
+			Bool("cached", true).
+			Duration("latency", 1500*time.Microsecond).
+			Info("request served")
+	}
+}
@@ -5,5 +5,7 @@ import (
 )
 
 var (
-	ErrLevel = errors.New("Invalid level")
+	ErrLevel   = errors.New("Invalid level")
+	ErrFilter  = errors.New("Invalid filter")
+	ErrVModule = errors.New("Invalid vmodule")
 )
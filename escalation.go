@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SetEscalation lowers min level of output to level for window when threshold
+// of error or more serious logs is reached within interval, and reverts it
+// automatically after. Each transition is logged at Lwarn level.
+// A threshold of 0 disables it.
+// NOTE: The escalation is shared by all loggers created with l.New().
+func (l *Logger) SetEscalation(threshold int, interval, window time.Duration, level Level) error {
+	if !level.IsValid() {
+		return ErrLevel
+	}
+
+	var esc *escalation
+	if threshold > 0 {
+		esc = &escalation{
+			logger:    l,
+			threshold: threshold,
+			interval:  interval,
+			window:    window,
+			level:     level,
+		}
+	}
+
+	if prev := l.tree.escalation.Swap(esc); prev != nil {
+		prev.stop()
+	}
+
+	return nil
+}
+
+// Escalated returns whether min level of output is lowered by escalation.
+func (l *Logger) Escalated() bool {
+	esc := l.tree.escalation.Load()
+	if esc == nil {
+		return false
+	}
+
+	_, ok := esc.escalated()
+	return ok
+}
+
+type escalation struct {
+	mux sync.Mutex
+
+	logger    *Logger
+	threshold int
+	interval  time.Duration
+	window    time.Duration
+	level     Level
+
+	// active is read without lock by level checks, and changed with lock held.
+	active atomic.Bool
+	count  int
+	since  time.Time
+	timer  *time.Timer
+}
+
+func (esc *escalation) escalated() (Level, bool) {
+	return esc.level, esc.active.Load()
+}
+
+// observe counts an error log and escalates if threshold is reached.
+func (esc *escalation) observe() {
+	now := time.Now()
+
+	esc.mux.Lock()
+	if now.Sub(esc.since) > esc.interval {
+		esc.since = now
+		esc.count = 0
+	}
+	esc.count++
+
+	if esc.count < esc.threshold {
+		esc.mux.Unlock()
+		return
+	}
+	esc.count = 0
+
+	// extend the window if it's escalated already
+	if esc.active.Load() {
+		esc.timer.Reset(esc.window)
+		esc.mux.Unlock()
+		return
+	}
+
+	esc.active.Store(true)
+	esc.timer = time.AfterFunc(esc.window, esc.revert)
+	esc.mux.Unlock()
+
+	esc.logger.Output(Lwarn, fmt.Sprintf("log level escalated to %s for %v after %d errors within %v", esc.level, esc.window, esc.threshold, esc.interval))
+}
+
+func (esc *escalation) revert() {
+	esc.mux.Lock()
+	if !esc.active.Load() {
+		esc.mux.Unlock()
+		return
+	}
+	esc.active.Store(false)
+	esc.mux.Unlock()
+
+	esc.logger.Output(Lwarn, fmt.Sprintf("log level reverted to %s", esc.logger.Level()))
+}
+
+func (esc *escalation) stop() {
+	esc.mux.Lock()
+	if esc.timer != nil {
+		esc.timer.Stop()
+	}
+	esc.active.Store(false)
+	esc.mux.Unlock()
+}
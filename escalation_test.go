@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(b []byte) (int, error) {
+	sb.mux.Lock()
+	defer sb.mux.Unlock()
+
+	return sb.buf.Write(b)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mux.Lock()
+	defer sb.mux.Unlock()
+
+	return sb.buf.String()
+}
+
+func Test_Logger_Escalation(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Lwarn)
+
+	err := logger.SetEscalation(2, time.Second, 50*time.Millisecond, Ldebug)
+	assert.Nil(t, err)
+
+	child := logger.New("child")
+	child.Debug("before escalation")
+	assert.NotContains(t, buf.String(), "before escalation")
+
+	logger.Error("error 1")
+	assert.False(t, child.Escalated())
+
+	child.Error("error 2")
+	assert.True(t, child.Escalated())
+	assert.Contains(t, buf.String(), "log level escalated to DEBUG")
+
+	child.Debug("during escalation")
+	assert.Contains(t, buf.String(), "during escalation")
+
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, child.Escalated())
+	assert.Contains(t, buf.String(), "log level reverted to WARN")
+
+	child.Debug("after escalation")
+	assert.NotContains(t, buf.String(), "after escalation")
+}
+
+func Test_Logger_EscalationWithInvalidLevel(t *testing.T) {
+	logger, _ := New("nil")
+
+	assert.Equal(t, ErrLevel, logger.SetEscalation(1, time.Second, time.Second, lmax))
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Escaping defines how control characters of messages are written in text format.
+type Escaping int
+
+const (
+	// EscapeAll escapes newlines and control characters, e.g. \n and \x1b,
+	// and strips foreign ANSI sequences. It's the default.
+	EscapeAll Escaping = iota
+
+	// EscapeIndent is the same as EscapeAll except that multi-line messages
+	// are written as continuation lines indented with a tab.
+	EscapeIndent
+
+	// EscapeNone writes messages verbatim.
+	EscapeNone
+)
+
+const hexDigits = "0123456789abcdef"
+
+// escapeText writes s to buf with control characters escaped and ANSI sequences stripped.
+func escapeText(buf *bytes.Buffer, s string, escape Escaping) {
+	if !needsEscape(s) {
+		buf.WriteString(s)
+		return
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == 0x1b:
+			i += ansiLen(s[i:])
+			continue
+
+		case c == '\n':
+			if escape == EscapeIndent {
+				buf.WriteString("\n\t")
+			} else {
+				buf.WriteString(`\n`)
+			}
+
+		case c == '\r':
+			buf.WriteString(`\r`)
+
+		case c == '\t':
+			buf.WriteByte(c)
+
+		case c < 0x20 || c == 0x7f:
+			buf.WriteString(`\x`)
+			buf.WriteByte(hexDigits[c>>4])
+			buf.WriteByte(hexDigits[c&0xf])
+
+		case c == 0xc2 && i+1 < len(s) && s[i+1] >= 0x80 && s[i+1] <= 0x9f:
+			// C1 control characters, e.g. U+009B works as CSI on some terminals
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[s[i+1]>>4])
+			buf.WriteByte(hexDigits[s[i+1]&0xf])
+			i += 2
+			continue
+
+		default:
+			buf.WriteByte(c)
+		}
+
+		i++
+	}
+}
+
+// stripANSI returns s without ANSI sequences.
+func stripANSI(s string) string {
+	if strings.IndexByte(s, 0x1b) < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			i += ansiLen(s[i:])
+			continue
+		}
+
+		b.WriteByte(s[i])
+		i++
+	}
+
+	return b.String()
+}
+
+// ansiLen returns length of ANSI sequence at the beginning of s, which starts with ESC.
+func ansiLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+
+	switch s[1] {
+	case '[':
+		// CSI, ends with a byte in 0x40-0x7e
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+
+		return len(s)
+
+	case ']':
+		// OSC, ends with BEL or ST
+		for i := 2; i < len(s); i++ {
+			if s[i] == 0x07 {
+				return i + 1
+			}
+			if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+
+		return len(s)
+	}
+
+	return 2
+}
+
+func needsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < 0x20 && c != '\t') || c == 0x7f || c == 0xc2 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// needsQuote returns whether s is empty or contains separators, quotes or
+// control characters, which could be mistaken for other fields.
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c <= ' ' || c == '=' || c == ',' || c == '"' || c == '\\' || c == 0x7f {
+				return true
+			}
+
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || !strconv.IsPrint(r) {
+			return true
+		}
+
+		i += size
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_Escape(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetTags("escape")
+
+	logger.Info("login failed for bob\n[ERROR, admin] - granted")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `login failed for bob\n[ERROR, admin] - granted`)
+
+	buf.Reset()
+	logger.Info("bell\a\x00 tab\tdel\x7f c1\u009b cr\r")
+	assert.Contains(t, buf.String(), `bell\x07\x00 tab`+"\t"+`del\x7f c1\u009b cr\r`)
+
+	buf.Reset()
+	logger.Info("trailing newline\n")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), "trailing newline\n")
+}
+
+func Test_Logger_EscapeANSI(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetColor(true)
+
+	logger.Warn("\x1b[2J\x1b[1;31mred\x1b[0m \x1b]0;title\x07done")
+
+	brush := brushes[Lwarn]
+	draw, clean := brush.Colour()
+
+	assert.Contains(t, buf.String(), draw)
+	assert.Contains(t, buf.String(), "red done\n"+clean)
+	assert.NotContains(t, buf.String(), "\x1b[2J")
+	assert.NotContains(t, buf.String(), "title")
+}
+
+func Test_Logger_EscapeIndent(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetEscape(EscapeIndent)
+
+	logger.Info("first\nsecond\nthird")
+	assert.Contains(t, buf.String(), "first\n\tsecond\n\tthird\n")
+
+	// children inherit mode of parent
+	buf.Reset()
+	logger.New("child").Info("a\nb")
+	assert.Contains(t, buf.String(), "a\n\tb\n")
+}
+
+func Test_Logger_EscapeNone(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetEscape(EscapeNone)
+
+	logger.Info("raw\n\x1b[1mbold")
+	assert.Contains(t, buf.String(), "raw\n\x1b[1mbold\n")
+}
+
+func Test_Logger_EscapeTags(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	logger.New("evil\n[ERROR]").Info("hello")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `evil\n[ERROR]`)
+}
+
+func Test_Logger_EscapeFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	logger.NewTextLogger().Str("user", "bob, role=admin").Str("empty", "").Str("name", "bob").Info("text")
+	assert.Contains(t, buf.String(), `user="bob, role=admin", empty="", name=bob, msg=text`)
+
+	buf.Reset()
+	logger.NewTextLogger().Str("note", "a\nb\x1b[31m").Info("text")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `note="a\nb\x1b[31m"`)
+
+	// keys are quoted the same as values
+	buf.Reset()
+	logger.NewTextLogger().Str("x\n[ERROR] - forged", "v").Str("a=b", "c").Info("text")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"x\n[ERROR] - forged"=v, "a=b"=c, msg=text`)
+}
+
+func Test_Logger_EscapeJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetSinks(&Sink{Name: "json", Writer: &buf, Format: JSONFormat})
+
+	logger.Info("a\nb\x1b[31mred")
+
+	var record map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "a\nbred", record["msg"])
+}
+
+func Benchmark_EscapeText(b *testing.B) {
+	var buf bytes.Buffer
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		escapeText(&buf, "user bob logged in from 127.0.0.1 with session abcdef", EscapeAll)
+	}
+}
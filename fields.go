@@ -2,8 +2,6 @@ package logger
 
 import (
 	"bytes"
-	"encoding/json"
-	"fmt"
 	"log/slog"
 	"time"
 )
@@ -14,53 +12,60 @@ const (
 )
 
 type (
-	// Attr for fields option
-	Attr func(as *attrs)
+	// Attr is a typed field of structured logs, it's the same as slog.Attr.
+	Attr slog.Attr
 
 	Formatter int
 )
 
 // String is shortcut for string field option.
 func String(key, value string) Attr {
-	return func(as *attrs) {
-		as.fields = append(as.fields, slog.String(key, value))
-	}
+	return Attr(slog.String(key, value))
 }
 
 // Bool is shortcut for bool field option.
 func Bool(key string, value bool) Attr {
-	return func(as *attrs) {
-		as.fields = append(as.fields, slog.Bool(key, value))
-	}
+	return Attr(slog.Bool(key, value))
+}
+
+// Int is shortcut for int field option.
+func Int(key string, value int) Attr {
+	return Attr(slog.Int(key, value))
+}
+
+// Float64 is shortcut for float64 field option.
+func Float64(key string, value float64) Attr {
+	return Attr(slog.Float64(key, value))
 }
 
 // Duration is shortcut for time.Duration field option.
 func Duration(key string, value time.Duration) Attr {
-	return func(as *attrs) {
-		as.fields = append(as.fields, slog.String(key, fmt.Sprintf("%v", value)))
-	}
+	return Attr(slog.Duration(key, value))
 }
 
 // Time is shortcut for time.Time field option.
 func Time(key string, value time.Time) Attr {
-	return func(as *attrs) {
-		as.fields = append(as.fields, slog.Time(key, value))
-	}
+	return Attr(slog.Time(key, value))
 }
 
 // Err is shortcut for error field option.
 // NOTE: It uses error for the key forced!
 func Err(err error) Attr {
-	return func(as *attrs) {
-		as.fields = append(as.fields, slog.Any("error", err.Error()))
-	}
+	return Attr(slog.String("error", err.Error()))
 }
 
 // Any for any type field option.
 func Any(key string, value any) Attr {
-	return func(as *attrs) {
-		as.fields = append(as.fields, slog.Any(key, value))
+	return Attr(slog.Any(key, value))
+}
+
+// appendAttrs appends attrs to fields.
+func appendAttrs(fields []slog.Attr, attrs ...Attr) []slog.Attr {
+	for _, attr := range attrs {
+		fields = append(fields, slog.Attr(attr))
 	}
+
+	return fields
 }
 
 type attrs struct {
@@ -78,41 +83,18 @@ func (as *attrs) IsValid() bool {
 }
 
 func (as *attrs) String() string {
-	n := len(as.fields) - 1
-
 	var buf bytes.Buffer
-	for i, attr := range as.fields {
-		buf.WriteString(attr.Key)
-		buf.WriteString("=")
-		buf.WriteString(attr.Value.String())
-
-		if n > i {
-			buf.WriteString(", ")
-		}
-	}
+
+	writeTextAttrs(&buf, as.fields)
 
 	return buf.String()
 }
 
 func (as *attrs) JSONString() string {
-	fields := make(map[string]any)
-	for _, attr := range as.fields {
-		switch attr.Value.Kind() {
-		case slog.KindString:
-			fields[attr.Key] = attr.Value.String()
-		case slog.KindBool:
-			fields[attr.Key] = attr.Value.Bool()
-		case slog.KindDuration:
-			fields[attr.Key] = fmt.Sprintf("%v", attr.Value.Duration())
-		case slog.KindTime:
-			fields[attr.Key] = attr.Value.Time()
-		case slog.KindAny:
-			fields[attr.Key] = attr.Value
-		default:
-			fields[attr.Key] = attr.Value.String()
-		}
-	}
+	var buf bytes.Buffer
 
-	b, _ := json.Marshal(fields)
-	return string(b)
+	enc := jsonEncoder{buf: &buf}
+	enc.encodeAttrs(as.fields, 0)
+
+	return buf.String()
 }
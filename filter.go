@@ -0,0 +1,539 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Filter is a compiled expression evaluated against records, e.g.
+//
+//	level >= warn && tag == "payments" && user_id != ""
+//
+// Operands are identifiers, quoted strings and numbers. Identifiers level, msg,
+// tag, file and line refer to the record, others refer to fields of the record,
+// a missing field is an empty string. Comparing with level resolves the other
+// side by level name, unknown names are rejected and patterns of =~ and !~ match
+// names of level. tag matches any of tags. Available operators are
+// == != > >= < <= =~ !~ && || ! and parentheses.
+type Filter struct {
+	expr string
+	root filterNode
+}
+
+// ParseFilter compiles expr into Filter.
+func ParseFilter(expr string) (*Filter, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{
+		tokens: tokens,
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrFilter, p.tokens[p.pos].text)
+	}
+
+	return &Filter{
+		expr: expr,
+		root: root,
+	}, nil
+}
+
+// Match returns whether the record matches the filter.
+func (f *Filter) Match(r *Record) bool {
+	return f.root.eval(r)
+}
+
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// SetDropFilter drops records matching expr, an empty expr disables it.
+// NOTE: Filters are shared by all loggers created with l.New().
+func (l *Logger) SetDropFilter(expr string) error {
+	var f *Filter
+	if expr != "" {
+		var err error
+
+		f, err = ParseFilter(expr)
+		if err != nil {
+			return err
+		}
+	}
+
+	l.rules.mux.Lock()
+	l.rules.drop = f
+	l.rules.mux.Unlock()
+
+	return nil
+}
+
+// SetRoute writes records matching expr only to the sink of name,
+// an empty expr removes the route.
+// NOTE: Routes are shared by all loggers created with l.New().
+func (l *Logger) SetRoute(name, expr string) error {
+	var f *Filter
+	if expr != "" {
+		var err error
+
+		f, err = ParseFilter(expr)
+		if err != nil {
+			return err
+		}
+	}
+
+	l.rules.mux.Lock()
+	if f == nil {
+		delete(l.rules.routes, name)
+	} else {
+		if l.rules.routes == nil {
+			l.rules.routes = make(map[string]*Filter)
+		}
+
+		l.rules.routes[name] = f
+	}
+	l.rules.mux.Unlock()
+
+	return nil
+}
+
+// rules holds filters shared by a logger tree.
+type rules struct {
+	mux sync.RWMutex
+
+	drop   *Filter
+	routes map[string]*Filter
+	levels atomic.Pointer[map[string]Level]
+}
+
+// dropped returns whether the record should be dropped.
+func (rs *rules) dropped(r *Record) bool {
+	if rs == nil {
+		return false
+	}
+
+	rs.mux.RLock()
+	drop := rs.drop
+	rs.mux.RUnlock()
+
+	return drop != nil && drop.Match(r)
+}
+
+// routed returns whether the record should be written to the sink of name.
+func (rs *rules) routed(name string, r *Record) bool {
+	if rs == nil {
+		return true
+	}
+
+	rs.mux.RLock()
+	route := rs.routes[name]
+	rs.mux.RUnlock()
+
+	return route == nil || route.Match(r)
+}
+
+type filterNode interface {
+	eval(r *Record) bool
+}
+
+type (
+	andNode struct {
+		left, right filterNode
+	}
+
+	orNode struct {
+		left, right filterNode
+	}
+
+	notNode struct {
+		node filterNode
+	}
+
+	compareNode struct {
+		op          string
+		left, right filterOperand
+		re          *regexp.Regexp
+	}
+)
+
+func (n *andNode) eval(r *Record) bool {
+	return n.left.eval(r) && n.right.eval(r)
+}
+
+func (n *orNode) eval(r *Record) bool {
+	return n.left.eval(r) || n.right.eval(r)
+}
+
+func (n *notNode) eval(r *Record) bool {
+	return !n.node.eval(r)
+}
+
+func (n *compareNode) eval(r *Record) bool {
+	// tag matches any of tags, or none of tags for negative operators
+	if n.left.ident == "tag" || n.right.ident == "tag" {
+		other := n.right
+		if n.right.ident == "tag" {
+			other = n.left
+		}
+
+		value := other.value(r)
+
+		if n.op == "!=" || n.op == "!~" {
+			for _, tag := range r.Tags {
+				if !n.compareStrings(tag, value) {
+					return false
+				}
+			}
+
+			return true
+		}
+
+		for _, tag := range r.Tags {
+			if n.compareStrings(tag, value) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// level resolves the other side by name, patterns match names of level
+	if n.re == nil && (n.left.ident == "level" || n.right.ident == "level") {
+		left, right := n.left.level(r), n.right.level(r)
+
+		return compareOrdered(n.op, int(left), int(right))
+	}
+
+	return n.compareStrings(n.left.value(r), n.right.value(r))
+}
+
+func (n *compareNode) compareStrings(left, right string) bool {
+	switch n.op {
+	case "=~":
+		return n.re.MatchString(left)
+
+	case "!~":
+		return !n.re.MatchString(left)
+	}
+
+	lf, lerr := strconv.ParseFloat(left, 64)
+	rf, rerr := strconv.ParseFloat(right, 64)
+	if lerr == nil && rerr == nil {
+		return compareOrdered(n.op, lf, rf)
+	}
+
+	return compareOrdered(n.op, left, right)
+}
+
+func compareOrdered[T int | float64 | string](op string, left, right T) bool {
+	switch op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case ">":
+		return left > right
+	case ">=":
+		return left >= right
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	}
+
+	return false
+}
+
+// filterOperand is an identifier or a literal.
+type filterOperand struct {
+	ident   string
+	literal string
+}
+
+func (o filterOperand) value(r *Record) string {
+	switch o.ident {
+	case "":
+		return o.literal
+	case "level":
+		return r.Level.String()
+	case "msg":
+		return r.Message
+	case "file":
+		return r.File
+	case "line":
+		return strconv.Itoa(r.Line)
+	}
+
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		if r.Fields[i].Key == o.ident {
+			return r.Fields[i].Value.String()
+		}
+	}
+
+	return ""
+}
+
+func (o filterOperand) level(r *Record) Level {
+	switch o.ident {
+	case "level":
+		return r.Level
+	case "":
+		return ResolveLevelByName(o.literal)
+	}
+
+	// bare identifier is a level name
+	return ResolveLevelByName(o.ident)
+}
+
+type filterToken struct {
+	kind int
+	text string
+}
+
+const (
+	tokenIdent = iota
+	tokenString
+	tokenNumber
+	tokenOp
+)
+
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '"':
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("%w: unterminated string at %d", ErrFilter, i)
+			}
+
+			s, err := strconv.Unquote(expr[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid string at %d", ErrFilter, i)
+			}
+
+			tokens = append(tokens, filterToken{kind: tokenString, text: s})
+			i = j + 1
+
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for ; j < len(expr) && (expr[j] == '.' || (expr[j] >= '0' && expr[j] <= '9')); j++ {
+			}
+
+			tokens = append(tokens, filterToken{kind: tokenNumber, text: expr[i:j]})
+			i = j
+
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			j := i + 1
+			for ; j < len(expr); j++ {
+				c := expr[j]
+				if c != '_' && c != '.' && c != '-' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+					break
+				}
+			}
+
+			tokens = append(tokens, filterToken{kind: tokenIdent, text: expr[i:j]})
+			i = j
+
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", ">=", "<=", "=~", "!~", ">", "<", "!", "(", ")"} {
+				if strings.HasPrefix(expr[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("%w: unexpected %q at %d", ErrFilter, c, i)
+			}
+
+			tokens = append(tokens, filterToken{kind: tokenOp, text: op})
+			i += len(op)
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek(op string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOp && p.tokens[p.pos].text == op
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek("||") {
+		p.pos++
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek("&&") {
+		p.pos++
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.peek("!") {
+		p.pos++
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notNode{node: node}, nil
+	}
+
+	if p.peek("(") {
+		p.pos++
+
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.peek(")") {
+			return nil, fmt.Errorf("%w: missing )", ErrFilter)
+		}
+		p.pos++
+
+		return node, nil
+	}
+
+	return p.parseCompare()
+}
+
+func (p *filterParser) parseCompare() (filterNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOp {
+		return nil, fmt.Errorf("%w: missing operator after %q", ErrFilter, p.tokens[p.pos-1].text)
+	}
+
+	op := p.tokens[p.pos].text
+	switch op {
+	case "==", "!=", ">", ">=", "<", "<=", "=~", "!~":
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrFilter, op)
+	}
+	p.pos++
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &compareNode{
+		op:    op,
+		left:  left,
+		right: right,
+	}
+
+	if op == "=~" || op == "!~" {
+		if right.ident != "" {
+			return nil, fmt.Errorf("%w: pattern of %s must be a string", ErrFilter, op)
+		}
+
+		node.re, err = regexp.Compile(right.literal)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFilter, err)
+		}
+
+		return node, nil
+	}
+
+	// unknown level names would match all levels silently
+	if left.ident == "level" || right.ident == "level" {
+		for _, operand := range []filterOperand{left, right} {
+			if operand.ident == "level" || operand.ident == "tag" {
+				continue
+			}
+
+			name := operand.ident
+			if name == "" {
+				name = operand.literal
+			}
+
+			if !ResolveLevelByName(name).IsValid() {
+				return nil, fmt.Errorf("%w: unknown level %q", ErrFilter, name)
+			}
+		}
+	}
+
+	return node, nil
+}
+
+func (p *filterParser) parseOperand() (filterOperand, error) {
+	if p.pos >= len(p.tokens) {
+		return filterOperand{}, fmt.Errorf("%w: unexpected end", ErrFilter)
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+
+	switch token.kind {
+	case tokenIdent:
+		return filterOperand{ident: token.text}, nil
+
+	case tokenString, tokenNumber:
+		return filterOperand{literal: token.text}, nil
+	}
+
+	return filterOperand{}, fmt.Errorf("%w: unexpected %q", ErrFilter, token.text)
+}
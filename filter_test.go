@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Filter_Match(t *testing.T) {
+	r := &Record{
+		Level:   Lwarn,
+		Tags:    []string{"payments", "api"},
+		Message: "charge declined",
+		Fields: []slog.Attr{
+			slog.String("user_id", "u-1"),
+			slog.Int("status", 402),
+		},
+	}
+
+	testCases := map[string]bool{
+		`level >= warn`:                 true,
+		`level > WARN`:                  false,
+		`level == "warn"`:               true,
+		`level =~ "^(WARN|ERROR)$"`:     true,
+		`tag == "payments"`:             true,
+		`tag != "payments"`:             false,
+		`tag != "db"`:                   true,
+		`tag =~ "^pay"`:                 true,
+		`user_id != ""`:                 true,
+		`missing == ""`:                 true,
+		`status >= 400 && status < 500`: true,
+		`status > 1000`:                 false,
+		`msg =~ "declined$"`:            true,
+		`msg !~ "declined"`:             false,
+		`!(level >= error) && (tag == "db" || tag == "api")`:  true,
+		`level >= warn && tag == "payments" && user_id != ""`: true,
+	}
+
+	for expr, expected := range testCases {
+		f, err := ParseFilter(expr)
+		assert.Nil(t, err, expr)
+		assert.Equal(t, expected, f.Match(r), expr)
+		assert.Equal(t, expr, f.String())
+	}
+}
+
+func Test_Filter_ParseError(t *testing.T) {
+	for _, expr := range []string{
+		`level >=`,
+		`level warn`,
+		`(level >= warn`,
+		`msg == "unterminated`,
+		`msg =~ "["`,
+		`msg =~ other`,
+		`level >= warn extra`,
+		`level # warn`,
+		`level >= wraning`,
+		`level == "unknown"`,
+		`3 < level`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.True(t, errors.Is(err, ErrFilter), expr)
+	}
+}
+
+func Test_Logger_SetDropFilter(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	child := logger.New("thirdparty")
+
+	// filters are shared by the tree
+	assert.Nil(t, logger.SetDropFilter(`tag == "thirdparty" && level < error`))
+
+	child.Info("noisy")
+	child.Error("important")
+	logger.Info("mine")
+
+	assert.NotContains(t, buf.String(), "noisy")
+	assert.Contains(t, buf.String(), "important")
+	assert.Contains(t, buf.String(), "mine")
+
+	assert.NotNil(t, logger.SetDropFilter(`tag ==`))
+	assert.Nil(t, logger.SetDropFilter(""))
+
+	child.Info("noisy again")
+	assert.Contains(t, buf.String(), "noisy again")
+}
+
+func Test_Logger_SetRoute(t *testing.T) {
+	var (
+		all, payments bytes.Buffer
+	)
+
+	logger, _ := New("stdout")
+	logger.SetSinks(
+		&Sink{Name: "all", Writer: &all},
+		&Sink{Name: "payments", Writer: &payments},
+	)
+	assert.Nil(t, logger.SetRoute("payments", `tag == "payments"`))
+
+	logger.New("payments").Info("charged")
+	logger.New("orders").Info("ordered")
+
+	assert.Contains(t, all.String(), "charged")
+	assert.Contains(t, all.String(), "ordered")
+	assert.Contains(t, payments.String(), "charged")
+	assert.NotContains(t, payments.String(), "ordered")
+
+	assert.Nil(t, logger.SetRoute("payments", ""))
+	logger.New("orders").Info("routed again")
+	assert.Equal(t, 1, strings.Count(payments.String(), "routed again"))
+}
@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	gateFirstN gateKind = iota
+	gateEveryN
+	gateEvery
+)
+
+// GatedLogger is a Logger gated by call site, e.g. l.Once() and l.V(2), all logs
+// of it are discarded if the gate is closed.
+type GatedLogger interface {
+	Print(v ...any)
+	Printf(format string, v ...any)
+	Debug(v ...any)
+	Debugf(format string, v ...any)
+	Info(v ...any)
+	Infof(format string, v ...any)
+	Warn(v ...any)
+	Warnf(format string, v ...any)
+	Error(v ...any)
+	Errorf(format string, v ...any)
+	Fatal(v ...any)
+	Fatalf(format string, v ...any)
+	Panic(v ...any)
+	Panicf(format string, v ...any)
+}
+
+var (
+	_ GatedLogger = (*Logger)(nil)
+	_ GatedLogger = nopLogger{}
+)
+
+type gateKind int
+
+type gateKey struct {
+	kind gateKind
+	pc   uintptr
+	key  string
+}
+
+type gate struct {
+	count atomic.Int64
+	last  atomic.Int64
+}
+
+// Once returns l for the first call of the call site or explicit key,
+// and a logger discards all logs after, e.g. l.Once().Warn("deprecated")
+// NOTE: States of gates are shared by all loggers created with l.New().
+func (l *Logger) Once(key ...string) GatedLogger {
+	if !l.tree.pass(gateFirstN, 1, 0, key) {
+		return nopLogger{}
+	}
+
+	return l
+}
+
+// FirstN returns l for the first n calls of the call site or explicit key,
+// and a logger discards all logs after.
+func (l *Logger) FirstN(n int, key ...string) GatedLogger {
+	if !l.tree.pass(gateFirstN, int64(n), 0, key) {
+		return nopLogger{}
+	}
+
+	return l
+}
+
+// EveryN returns l for the first and every n calls of the call site or explicit key,
+// and a logger discards all logs for others, e.g. l.EveryN(100).Debugf("%d done", i)
+func (l *Logger) EveryN(n int, key ...string) GatedLogger {
+	if !l.tree.pass(gateEveryN, int64(n), 0, key) {
+		return nopLogger{}
+	}
+
+	return l
+}
+
+// Every returns l at most once per d of the call site or explicit key,
+// and a logger discards all logs for others, e.g. l.Every(time.Minute).Infof("%d pending", n)
+func (l *Logger) Every(d time.Duration, key ...string) GatedLogger {
+	if !l.tree.pass(gateEvery, 0, d, key) {
+		return nopLogger{}
+	}
+
+	return l
+}
+
+// pass returns whether the gate of caller is open.
+// NOTE: It must be called by gate methods directly for resolving call site.
+func (t *tree) pass(kind gateKind, n int64, d time.Duration, key []string) bool {
+	gk := gateKey{
+		kind: kind,
+	}
+	if len(key) > 0 {
+		gk.key = key[0]
+	} else {
+		pc, _, _, _ := runtime.Caller(2)
+		gk.pc = pc
+	}
+
+	v, ok := t.gates.Load(gk)
+	if !ok {
+		v, _ = t.gates.LoadOrStore(gk, new(gate))
+	}
+	g := v.(*gate)
+
+	switch kind {
+	case gateFirstN:
+		return g.count.Add(1) <= n
+
+	case gateEveryN:
+		if n <= 1 {
+			return true
+		}
+
+		return (g.count.Add(1)-1)%n == 0
+
+	case gateEvery:
+		now := time.Now().UnixNano()
+		for {
+			last := g.last.Load()
+			if last != 0 && now-last < int64(d) {
+				return false
+			}
+
+			if g.last.CompareAndSwap(last, now) {
+				return true
+			}
+		}
+	}
+
+	return true
+}
+
+func (log *structLog) Once(key ...string) StructLogger {
+	if !log.logger.tree.pass(gateFirstN, 1, 0, key) {
+		return nopLog{}
+	}
+
+	return log
+}
+
+func (log *structLog) FirstN(n int, key ...string) StructLogger {
+	if !log.logger.tree.pass(gateFirstN, int64(n), 0, key) {
+		return nopLog{}
+	}
+
+	return log
+}
+
+func (log *structLog) EveryN(n int, key ...string) StructLogger {
+	if !log.logger.tree.pass(gateEveryN, int64(n), 0, key) {
+		return nopLog{}
+	}
+
+	return log
+}
+
+func (log *structLog) Every(d time.Duration, key ...string) StructLogger {
+	if !log.logger.tree.pass(gateEvery, 0, d, key) {
+		return nopLog{}
+	}
+
+	return log
+}
+
+// nopLog is returned by closed gates of StructLogger, it discards all logs.
+type nopLog struct{}
+
+func (nop nopLog) Str(key, value string) StructLogger                    { return nop }
+func (nop nopLog) Bool(key string, value bool) StructLogger              { return nop }
+func (nop nopLog) Int(key string, value int) StructLogger                { return nop }
+func (nop nopLog) Float64(key string, value float64) StructLogger        { return nop }
+func (nop nopLog) Duration(key string, value time.Duration) StructLogger { return nop }
+func (nop nopLog) Time(key string, value time.Time) StructLogger         { return nop }
+func (nop nopLog) Err(err error, stack bool) StructLogger                { return nop }
+func (nop nopLog) Any(key string, value any) StructLogger                { return nop }
+func (nop nopLog) Fields(fields map[string]any) StructLogger             { return nop }
+func (nop nopLog) Once(key ...string) StructLogger                       { return nop }
+func (nop nopLog) FirstN(n int, key ...string) StructLogger              { return nop }
+func (nop nopLog) EveryN(n int, key ...string) StructLogger              { return nop }
+func (nop nopLog) Every(d time.Duration, key ...string) StructLogger     { return nop }
+func (nop nopLog) V(n int) StructLogger                                  { return nop }
+func (nop nopLog) Debug(msg string)                                      {}
+func (nop nopLog) Debugf(format string, args ...any)                     {}
+func (nop nopLog) Info(msg string)                                       {}
+func (nop nopLog) Infof(format string, args ...any)                      {}
+func (nop nopLog) Warn(msg string)                                       {}
+func (nop nopLog) Warnf(format string, args ...any)                      {}
+func (nop nopLog) Error(msg string)                                      {}
+func (nop nopLog) Errorf(format string, args ...any)                     {}
+func (nop nopLog) Fatal(msg string)                                      {}
+func (nop nopLog) Fatalf(format string, args ...any)                     {}
+func (nop nopLog) Panic(msg string)                                      {}
+func (nop nopLog) Panicf(format string, args ...any)                     {}
+
+// nopLogger is returned by closed gates of Logger, it discards all logs.
+type nopLogger struct{}
+
+func (nop nopLogger) Print(v ...any)                 {}
+func (nop nopLogger) Printf(format string, v ...any) {}
+func (nop nopLogger) Debug(v ...any)                 {}
+func (nop nopLogger) Debugf(format string, v ...any) {}
+func (nop nopLogger) Info(v ...any)                  {}
+func (nop nopLogger) Infof(format string, v ...any)  {}
+func (nop nopLogger) Warn(v ...any)                  {}
+func (nop nopLogger) Warnf(format string, v ...any)  {}
+func (nop nopLogger) Error(v ...any)                 {}
+func (nop nopLogger) Errorf(format string, v ...any) {}
+func (nop nopLogger) Fatal(v ...any)                 {}
+func (nop nopLogger) Fatalf(format string, v ...any) {}
+func (nop nopLogger) Panic(v ...any)                 {}
+func (nop nopLogger) Panicf(format string, v ...any) {}
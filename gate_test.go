@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_Once(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	for i := 0; i < 3; i++ {
+		logger.Once().Warn("deprecated")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "deprecated"))
+
+	// explicit key is shared by call sites
+	logger.Once("testing-once").Warn("keyed")
+	logger.Once("testing-once").Warn("keyed")
+	assert.Equal(t, 1, strings.Count(buf.String(), "keyed"))
+}
+
+func Test_Logger_FirstN(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	for i := 0; i < 5; i++ {
+		logger.FirstN(2).Infof("first #%d", i)
+	}
+	assert.Equal(t, 2, strings.Count(buf.String(), "first #"))
+}
+
+func Test_Logger_EveryN(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	for i := 0; i < 10; i++ {
+		logger.EveryN(4).Debugf("every #%d", i)
+	}
+	assert.Equal(t, 3, strings.Count(buf.String(), "every #"))
+	assert.Contains(t, buf.String(), "every #0")
+	assert.Contains(t, buf.String(), "every #4")
+	assert.Contains(t, buf.String(), "every #8")
+}
+
+func Test_Logger_Every(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			logger.Every(50*time.Millisecond).Infof("every #%d", j)
+		}
+
+		time.Sleep(60 * time.Millisecond)
+	}
+	assert.Equal(t, 2, strings.Count(buf.String(), "every #0"))
+	assert.NotContains(t, buf.String(), "every #1")
+}
+
+func Test_StructLogger_Gates(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	for i := 0; i < 3; i++ {
+		logger.NewTextLogger().Once().Str("key", "value").Warn("struct once")
+		logger.NewJsonLogger().EveryN(2).Any("n", i).Info("struct every")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "struct once"))
+	assert.Equal(t, 2, strings.Count(buf.String(), "struct every"))
+}
+
+func Test_Logger_GatesOfTree(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	other, _ := New("stdout")
+	other.SetOutput(&buf)
+
+	// states of gates are shared by a tree only
+	for _, l := range []*Logger{logger, logger.New("child"), other} {
+		l.Once("testing-tree").Warn("keyed")
+	}
+	assert.Equal(t, 2, strings.Count(buf.String(), "keyed"))
+
+	// closed gates discard all logs without exiting
+	closed := logger.Once("testing-tree")
+	assert.Equal(t, GatedLogger(nopLogger{}), closed)
+	closed.Fatal("fatal")
+	closed.Panicf("panic %d", 1)
+	assert.NotContains(t, buf.String(), "fatal")
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	maxPooledFields = 64
+	maxPooledStacks = 64 << 10
+)
+
+var (
+	recordPool = sync.Pool{
+		New: func() any {
+			return new(Record)
+		},
+	}
+)
+
+type (
+	// Hook observes and mutates records before encoding.
+	// It returns false to veto the record.
+	// NOTE: Slices of Record are shared, replace them instead of modifying in place.
+	// Records are reused after writing, hooks must not keep them after Fire.
+	Hook interface {
+		Fire(r *Record) bool
+	}
+
+	// HookFunc is an adapter of func as Hook.
+	HookFunc func(r *Record) bool
+)
+
+// Fire implements Hook interface.
+func (fn HookFunc) Fire(r *Record) bool {
+	return fn(r)
+}
+
+// Record is a log passed to hooks before encoding.
+type Record struct {
+	Level   Level
+	Time    time.Time
+	Tags    []string
+	Fields  []slog.Attr
+	Message string
+	File    string
+	Line    int
+
+	format Formatter
+	stacks []byte
+
+	// own is storage of fields owned by the record, it's reused by pool.
+	own []slog.Attr
+}
+
+func (r *Record) attrs() *attrs {
+	return &attrs{
+		format: r.format,
+		fields: r.Fields,
+		stacks: r.stacks,
+	}
+}
+
+// clone returns a copy of the record which doesn't share fields with it,
+// records kept after writing must be cloned for pooling.
+func (r *Record) clone() Record {
+	c := *r
+	c.Fields = slices.Clone(r.Fields)
+	c.stacks = slices.Clone(r.stacks)
+	c.own = nil
+
+	return c
+}
+
+func (l *Logger) newRecord(level Level, as *attrs, msg string, tags []string, file string, line int) *Record {
+	r := recordPool.Get().(*Record)
+
+	own, stacks := r.own[:0], r.stacks[:0]
+	*r = Record{
+		Level:   level,
+		Time:    time.Now(),
+		Tags:    tags,
+		Message: msg,
+		File:    file,
+		Line:    line,
+		stacks:  stacks,
+	}
+	if as != nil {
+		if len(as.fields) > 0 {
+			own = append(own, as.fields...)
+			r.Fields = own
+		}
+		if len(as.stacks) > 0 {
+			// stacks is copied for keeping attrs on stack
+			r.stacks = append(r.stacks, as.stacks...)
+		}
+		r.format = as.format
+	}
+	r.own = own
+
+	return r
+}
+
+// freeRecord puts the record back to pool, it must not be used after it.
+func freeRecord(r *Record) {
+	if cap(r.own) > maxPooledFields || cap(r.stacks) > maxPooledStacks {
+		return
+	}
+
+	clear(r.own)
+	*r = Record{
+		stacks: r.stacks[:0],
+		own:    r.own[:0],
+	}
+
+	recordPool.Put(r)
+}
+
+// AddHook registers hooks of Logger, they're invoked in order for each record.
+// NOTE: Hooks are inherited by loggers created with l.New(), including ones added
+// after it, and they're invoked before hooks of the loggers.
+func (l *Logger) AddHook(hooks ...Hook) {
+	l.mux.Lock()
+	l.hooks = append(l.hooks[:len(l.hooks):len(l.hooks)], hooks...)
+	l.mux.Unlock()
+}
+
+// fire invokes hooks inherited and hooks of l with r, it returns false if any hook vetoes it.
+func (l *Logger) fire(r *Record) bool {
+	return l.fireHooks(l, r)
+}
+
+// fireHooks invokes hooks of parents of owner and then hooks of owner.
+func (l *Logger) fireHooks(owner *Logger, r *Record) bool {
+	if owner.parent != nil && !l.fireHooks(owner.parent, r) {
+		return false
+	}
+
+	owner.mux.RLock()
+	hooks := owner.hooks
+	owner.mux.RUnlock()
+
+	for _, hook := range hooks {
+		if !l.fireHook(hook, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fireHook contains panic of hook and reports it, the record is kept.
+func (l *Logger) fireHook(hook Hook, r *Record) (ok bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			ok = true
+
+			record := l.newRecord(Lerror, nil, fmt.Sprintf("hook %T panic: %v", hook, v), r.Tags, r.File, r.Line)
+			defer freeRecord(record)
+
+			_ = l.write(record)
+		}
+	}()
+
+	return hook.Fire(r)
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_AddHook(t *testing.T) {
+	var (
+		buf bytes.Buffer
+
+		fired []Level
+	)
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetTags("hook")
+	logger.AddHook(HookFunc(func(r *Record) bool {
+		fired = append(fired, r.Level)
+
+		assert.Equal(t, []string{"hook"}, r.Tags)
+		assert.False(t, r.Time.IsZero())
+
+		return r.Message != "vetoed"
+	}))
+
+	logger.Info("hello")
+	logger.Warn("vetoed")
+
+	assert.Equal(t, []Level{Linfo, Lwarn}, fired)
+	assert.Contains(t, buf.String(), "hello")
+	assert.NotContains(t, buf.String(), "vetoed")
+}
+
+func Test_Logger_AddHookWithMutation(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.AddHook(HookFunc(func(r *Record) bool {
+		r.Fields = append(r.Fields, slog.String("host", "localhost"))
+		r.Message = "mutated " + r.Message
+
+		return true
+	}))
+
+	// hooks are inherited by children
+	logger.New("child").NewJsonLogger().Str("key", "value").Info("hello")
+	assert.Contains(t, buf.String(), "[INFO, child]")
+	assert.Contains(t, buf.String(), `{"host":"localhost","key":"value"} mutated hello`)
+}
+
+func Test_Logger_AddHookWithPanic(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.AddHook(HookFunc(func(r *Record) bool {
+		panic("broken hook")
+	}))
+
+	assert.NotPanics(t, func() {
+		logger.Info("hello")
+	})
+	assert.Contains(t, buf.String(), "[ERROR] - ")
+	assert.Contains(t, buf.String(), "hook logger.HookFunc panic: broken hook")
+	assert.Contains(t, buf.String(), "hello")
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"strings"
+	"sync/atomic"
 )
 
 const (
@@ -33,6 +34,40 @@ var (
 
 type Level int
 
+// LevelVar is a level which can be changed atomically, it's shared by
+// a logger and loggers created with l.New() after it until they set their own.
+type LevelVar struct {
+	level atomic.Int64
+}
+
+// NewLevelVar creates a LevelVar with level.
+func NewLevelVar(level Level) *LevelVar {
+	lv := &LevelVar{}
+	lv.level.Store(int64(level))
+
+	return lv
+}
+
+// Level returns current level of the var.
+func (lv *LevelVar) Level() Level {
+	return Level(lv.level.Load())
+}
+
+// Set changes level of the var, it returns ErrLevel for invalid level.
+func (lv *LevelVar) Set(level Level) error {
+	if !level.IsValid() {
+		return ErrLevel
+	}
+
+	lv.level.Store(int64(level))
+
+	return nil
+}
+
+func (lv *LevelVar) String() string {
+	return lv.Level().String()
+}
+
 func (l Level) IsValid() bool {
 	return lmin < l && l < lmax
 }
@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"bytes"
+	"io"
+	"sync"
 	"testing"
 
 	"github.com/golib/assert"
@@ -26,3 +29,124 @@ func Test_Level_ResolveLevelByName(t *testing.T) {
 
 	assertion.Equal(lmin, ResolveLevelByName("UNKNOWN"))
 }
+
+func Test_Level_LevelVar(t *testing.T) {
+	assertion := assert.New(t)
+
+	lv := NewLevelVar(Lwarn)
+	assertion.Equal(Lwarn, lv.Level())
+	assertion.Equal("WARN", lv.String())
+
+	assertion.Nil(lv.Set(Ldebug))
+	assertion.Equal(Ldebug, lv.Level())
+
+	assertion.Equal(ErrLevel, lv.Set(lmax))
+	assertion.Equal(Ldebug, lv.Level())
+}
+
+func Test_Logger_SharedLevel(t *testing.T) {
+	assertion := assert.New(t)
+
+	var buf bytes.Buffer
+
+	root, _ := New("stdout")
+	root.SetOutput(&buf)
+
+	child := root.New("child")
+	grandchild := child.New("grandchild")
+
+	// level of root is shared by the tree
+	assertion.Nil(root.SetLevel(Lwarn))
+	assertion.Equal(Lwarn, child.Level())
+	assertion.Equal(Lwarn, grandchild.Level())
+	assertion.False(grandchild.Enabled(Linfo))
+	assertion.True(grandchild.Enabled(Lerror))
+
+	grandchild.Info("hidden")
+	assertion.Empty(buf.String())
+
+	// override of child is shared by its descendants only
+	override := NewLevelVar(Ldebug)
+	child.SetLevelVar(override)
+	assertion.Equal(override, child.LevelVar())
+	assertion.Equal(Lwarn, root.Level())
+	assertion.Equal(Lwarn, grandchild.Level())
+	assertion.Equal(Ldebug, child.New("other").Level())
+
+	child.Debug("visible")
+	assertion.Contains(buf.String(), "visible")
+
+	assertion.Nil(override.Set(Lerror))
+	assertion.False(child.Enabled(Lwarn))
+	assertion.True(root.Enabled(Lwarn))
+}
+
+func Test_Logger_SetLevelOfChild(t *testing.T) {
+	assertion := assert.New(t)
+
+	root, _ := New("stdout")
+	root.SetOutput(io.Discard)
+
+	child := root.New("child")
+	sibling := root.New("sibling")
+	scope := root.NewScope(Lerror)
+	defer scope.Close()
+
+	// level of child is copied on write
+	assertion.Nil(child.SetLevel(Lerror))
+	assertion.Nil(scope.SetLevel(Lfatal))
+	assertion.Equal(Lerror, child.Level())
+	assertion.Equal(Lfatal, scope.Level())
+	assertion.Equal(lmin, root.Level())
+	assertion.Equal(lmin, sibling.Level())
+
+	// others still share level of root
+	assertion.Nil(root.SetLevel(Lwarn))
+	assertion.Equal(Lwarn, sibling.Level())
+	assertion.Equal(Lwarn, sibling.New("grandchild").Level())
+	assertion.Equal(Lerror, child.Level())
+
+	// descendants created after share level of child
+	grandchild := child.New("grandchild")
+	assertion.Nil(child.SetLevel(Ldebug))
+	assertion.Equal(Ldebug, grandchild.Level())
+
+	assertion.Equal(ErrLevel, sibling.SetLevel(lmax))
+	assertion.Equal(Lwarn, root.Level())
+}
+
+func Test_Logger_SharedLevelRace(t *testing.T) {
+	root, _ := New("stdout")
+	root.SetOutput(io.Discard)
+
+	child := root.New("child")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				root.SetLevel(Level(j%int(Llog) + 1))
+				child.SetLevelVar(NewLevelVar(Linfo))
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				child.Enabled(Linfo)
+				child.Level()
+				root.Tags()
+				root.Flag()
+				root.Skip()
+				child.Info("race")
+			}
+		}()
+	}
+
+	wg.Wait()
+}
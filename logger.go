@@ -3,13 +3,15 @@ package logger
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
-	"time"
+	"sync/atomic"
 
 	"github.com/dolab/colorize"
 )
@@ -29,42 +31,65 @@ var (
 		Ltrace: colorize.New("green"),
 		Llog:   colorize.New("black"),
 	}
+
+	// colours caches sequences of brushes
+	colours = func() map[Level][2]string {
+		m := make(map[Level][2]string, len(brushes))
+		for level, brush := range brushes {
+			draw, clean := brush.Colour()
+
+			m[level] = [2]string{draw, clean}
+		}
+
+		return m
+	}()
 )
 
 type Logger struct {
 	mux sync.RWMutex
 
-	out io.Writer
-	buf *bytes.Buffer
+	out    io.Writer
+	writer *sharedWriter
+	path   string
 
-	level    Level
-	tags     []string
+	// level and tags are read without locks for fast checks, the level is
+	// shared with the parent until it's set by the logger.
+	level    atomic.Pointer[LevelVar]
+	ownLevel bool
+	tags     atomic.Pointer[[]string]
 	flag     int
 	skip     int
 	colorful bool
+	escape   Escaping
+
+	scope *scopeBuffer
+
+	// parent is the logger derived from, hooks and sinks are inherited from it.
+	parent   *Logger
+	hooks    []Hook
+	sinks    []*Sink
+	ownSinks bool
+
+	tree    *tree
+	rules   *rules
+	verbose *verbosity
 }
 
 // New creates a logger with the requested output. (default to stderr)
 // NOTE: available outputs are [stdout|stderr|null|nil|path/to/file]
 func New(output string) (*Logger, error) {
-	colorful := runtime.GOOS != "windows"
+	var (
+		out      io.Writer
+		path     string
+		colorful = runtime.GOOS != "windows"
+	)
 
 	switch output {
 	case "stdout":
-		return &Logger{
-			out:      os.Stdout,
-			flag:     flag,
-			skip:     2,
-			colorful: colorful,
-		}, nil
+		out = os.Stdout
 
 	case "stderr":
-		return &Logger{
-			out:      os.Stderr,
-			flag:     flag,
-			skip:     2,
-			colorful: colorful,
-		}, nil
+		out = os.Stderr
 
 	default:
 		if output == "null" || output == "nil" {
@@ -76,94 +101,149 @@ func New(output string) (*Logger, error) {
 			return nil, fmt.Errorf("failed to open log file %s: %v", output, err)
 		}
 
-		return &Logger{
-			out:      file,
-			flag:     flag,
-			skip:     2,
-			colorful: false,
-		}, nil
+		if output != os.DevNull {
+			path = output
+		}
+
+		out = file
+		colorful = false
+	}
+
+	l := &Logger{
+		out:      out,
+		writer:   newSharedWriter(),
+		path:     path,
+		ownLevel: true,
+		flag:     flag,
+		skip:     2,
+		colorful: colorful,
+		tree:     newTree(),
+		rules:    &rules{},
+		verbose:  &verbosity{},
 	}
+	l.level.Store(&LevelVar{})
+	l.tags.Store(new([]string))
+
+	return l, nil
 }
 
 // New allocates a new Logger for given tags shared.
+// NOTE: Writes of loggers created with l.New() are serialized with l, each log is
+// written as a whole line without interleaving, even after SetOutput() of them.
+// Hooks and sinks of l are inherited by the new logger, including ones added
+// after it, and features like sampling and rate limits are shared by the tree.
 func (l *Logger) New(tags ...string) *Logger {
-	return &Logger{
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
+	child := &Logger{
 		mux:      sync.RWMutex{},
 		out:      l.out,
-		buf:      bytes.NewBuffer(nil),
-		level:    l.level,
-		tags:     tags,
+		writer:   l.writer,
+		path:     l.path,
 		flag:     l.flag,
 		skip:     l.skip,
 		colorful: l.colorful,
+		escape:   l.escape,
+		scope:    l.scope,
+		parent:   l,
+		tree:     l.tree,
+		rules:    l.rules,
+		verbose:  l.verbose,
 	}
+	child.level.Store(l.level.Load())
+	child.tags.Store(&tags)
+
+	return child
 }
 
 // NewTextLogger returns a new StructLogger with text formatter.
 func (l *Logger) NewTextLogger(attrs ...Attr) StructLogger {
-	return &structLog{
-		writer: l.output,
-		format: TextFormat,
-		attrs:  attrs,
-	}
+	return l.newStructLog(TextFormat, attrs)
 }
 
 // NewJsonLogger returns a new StructLogger with json formatter.
 func (l *Logger) NewJsonLogger(attrs ...Attr) StructLogger {
-	return &structLog{
-		writer: l.output,
-		format: JSONFormat,
-		attrs:  attrs,
-	}
+	return l.newStructLog(JSONFormat, attrs)
 }
 
-// SetLevel sets min level of output
+// SetLevel sets min level of output.
+// NOTE: The level is shared by loggers created with l.New() until they set their
+// own, see SetLevelVar for sharing a level explicitly.
 func (l *Logger) SetLevel(level Level) error {
 	if !level.IsValid() {
 		return ErrLevel
 	}
 
 	l.mux.Lock()
-	l.level = level
-	l.mux.Unlock()
+	defer l.mux.Unlock()
+
+	// level shared with the parent is copied on write
+	if !l.ownLevel {
+		l.level.Store(NewLevelVar(level))
+		l.ownLevel = true
+
+		return nil
+	}
 
-	return nil
+	return l.level.Load().Set(level)
 }
 
 // SetLevelByName sets min level of output by name,
 // available values are [debug|info|warn|error|fatal|panic|stack].
 // It returns ErrLevel for invalid name.
 func (l *Logger) SetLevelByName(name string) error {
-	level := ResolveLevelByName(name)
-	if !level.IsValid() {
-		return ErrLevel
-	}
+	return l.SetLevel(ResolveLevelByName(name))
+}
 
+// SetLevelVar replaces level of the logger with lv, it's useful for sharing
+// level of loggers explicitly, SetLevel of them changes lv, e.g.
+// child.SetLevelVar(logger.NewLevelVar(logger.Ldebug))
+func (l *Logger) SetLevelVar(lv *LevelVar) {
 	l.mux.Lock()
-	l.level = level
+	l.level.Store(lv)
+	l.ownLevel = true
 	l.mux.Unlock()
+}
+
+// inheritLevel replaces level of the logger with lv which is copied on write.
+func (l *Logger) inheritLevel(lv *LevelVar) {
+	l.mux.Lock()
+	l.level.Store(lv)
+	l.ownLevel = false
+	l.mux.Unlock()
+}
 
-	return nil
+// LevelVar returns level var of the logger.
+func (l *Logger) LevelVar() *LevelVar {
+	return l.level.Load()
 }
 
 func (l *Logger) Level() Level {
-	return l.level
+	return l.level.Load().Level()
+}
+
+// Enabled returns whether logs of level are written by the logger.
+func (l *Logger) Enabled(level Level) bool {
+	return l.minLevel() <= level
 }
 
 // SetTags sets tags of all logs, it'll replace previous definition.
 func (l *Logger) SetTags(tags ...string) {
 	l.mux.Lock()
-	l.tags = tags
+	l.tags.Store(&tags)
 	l.mux.Unlock()
 }
 
 // AddTags adds new tags to all logs, duplicated tags will be ignored.
 func (l *Logger) AddTags(tags ...string) {
 	l.mux.Lock()
+	oldTags := *l.tags.Load()
+
 	var newTags []string
 	for _, tag := range tags {
 		found := false
-		for _, existedTag := range l.tags {
+		for _, existedTag := range oldTags {
 			if tag == existedTag {
 				found = true
 				break
@@ -176,13 +256,14 @@ func (l *Logger) AddTags(tags ...string) {
 	}
 
 	if len(newTags) > 0 {
-		l.tags = append(l.tags, newTags...)
+		newTags = append(oldTags[:len(oldTags):len(oldTags)], newTags...)
+		l.tags.Store(&newTags)
 	}
 	l.mux.Unlock()
 }
 
 func (l *Logger) Tags() []string {
-	return l.tags
+	return *l.tags.Load()
 }
 
 // SetFlag changes flag of source file path format
@@ -193,6 +274,9 @@ func (l *Logger) SetFlag(flag int) {
 }
 
 func (l *Logger) Flag() int {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
 	return l.flag
 }
 
@@ -204,6 +288,9 @@ func (l *Logger) SetSkip(depth int) {
 }
 
 func (l *Logger) Skip() int {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+
 	return l.skip
 }
 
@@ -214,6 +301,14 @@ func (l *Logger) SetColor(colorful bool) {
 	l.mux.Unlock()
 }
 
+// SetEscape sets how control characters and ANSI escapes of messages are
+// written, default to EscapeAll.
+func (l *Logger) SetEscape(escape Escaping) {
+	l.mux.Lock()
+	l.escape = escape
+	l.mux.Unlock()
+}
+
 // SetOutput sets output of Logger
 func (l *Logger) SetOutput(w io.Writer) {
 	l.mux.Lock()
@@ -221,6 +316,15 @@ func (l *Logger) SetOutput(w io.Writer) {
 	l.mux.Unlock()
 }
 
+// currentOutput returns output of the logger.
+func (l *Logger) currentOutput() io.Writer {
+	l.mux.RLock()
+	out := l.out
+	l.mux.RUnlock()
+
+	return out
+}
+
 // Output writes the output for a logging event.
 // The string s contains the text to print after the tags specified
 // by the flags of the Logger.
@@ -234,75 +338,366 @@ func (l *Logger) output(level Level, as *attrs, msg string) error {
 		level = Linfo
 	}
 
-	var (
-		file                  string
-		line                  int
-		ok                    bool
-		colorDraw, colorClean string
-	)
+	// snapshot settings once per record
+	l.mux.RLock()
+	flag, skip, tags := l.flag, l.skip, *l.tags.Load()
+	l.mux.RUnlock()
+
+	file, line := l.caller(flag, skip)
+
+	return l.outputAt(level, as, msg, tags, file, line)
+}
+
+// outputAt is the same as output with source position given, e.g. where a
+// panic recovered occurs.
+func (l *Logger) outputAt(level Level, as *attrs, msg string, tags []string, file string, line int) error {
+	r := l.newRecord(level, as, msg, tags, file, line)
+	defer freeRecord(r)
 
-	if l.colorful {
-		brush := brushes[level]
-		colorDraw, colorClean = brush.Colour()
+	if !l.fire(r) || l.rules.dropped(r) {
+		return nil
 	}
 
-	if l.flag&(log.Lshortfile|log.Llongfile) != 0 {
-		_, file, line, ok = runtime.Caller(l.skip)
-		if !ok {
-			file = "???"
-			line = 0
+	l.tree.limits.Load().truncate(r)
+
+	if esc := l.tree.escalation.Load(); esc != nil && r.Level >= Lerror && r.Level <= Ltrace {
+		esc.observe()
+	}
+
+	if lim := l.tree.limiter.Load(); lim != nil && !lim.allow(r) {
+		return nil
+	}
+
+	if d := l.tree.dedup.Load(); d != nil && !d.pass(l, r) {
+		return nil
+	}
+
+	return l.write(r)
+}
+
+// write writes the record, it's kept by scope if enabled. Logs kept by flight
+// recorder are dumped before error or more serious records.
+func (l *Logger) write(r *Record) error {
+	if l.scope != nil {
+		evicted, ok := l.scope.add(r, true)
+		if ok {
+			if evicted.enabled {
+				return l.emit(&evicted.Record, false)
+			}
+
+			return nil
+		}
+
+		// buffered logs come first if the record is not buffered
+		if err := l.scope.flush(l); err != nil {
+			return err
 		}
 	}
 
-	// avoid data race
-	l.mux.Lock()
-	defer l.mux.Unlock()
+	// records written are not kept, they're in the output already
+	if recorder := l.recorder(); recorder != nil && r.Level >= Lerror && r.Level <= Ltrace {
+		recorder.dump(l, r.File, r.Line)
+	}
+
+	return l.emit(r, false)
+}
 
-	if l.buf == nil {
-		l.buf = bytes.NewBuffer(nil)
+// emit formats and writes the record to output, or sinks if defined.
+// Levels of sinks are ignored if forced.
+// NOTE: Records are encoded concurrently into pooled buffers, only writes are serialized.
+func (l *Logger) emit(r *Record, forced bool) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	// the read lock excludes setters only
+	sinks := l.Sinks()
+
+	l.mux.RLock()
+	out := l.out
+	if len(sinks) == 0 {
+		l.format(buf, r, l.colorful)
 	}
+	l.mux.RUnlock()
+
+	if len(sinks) == 0 {
+		_, err := l.writer.write(out, buf.Bytes())
 
-	// try to flush old data
-	if l.buf.Len() > 0 {
-		l.buf.WriteTo(l.out)
+		return err
 	}
-	l.buf.Reset()
 
-	l.buf.WriteString(colorDraw)
+	var errs []error
+	for _, sink := range sinks {
+		if !forced && r.Level < sink.Level {
+			continue
+		}
+
+		if !l.rules.routed(sink.Name, r) {
+			continue
+		}
 
-	l.formatHeader(level, file, line)
-	if as.IsValid() {
-		switch as.format {
+		buf.Reset()
+
+		l.mux.RLock()
+		switch sink.Format {
+		case JSONFormat:
+			l.formatJSON(buf, r)
+		default:
+			l.format(buf, r, sink.Color)
+		}
+		l.mux.RUnlock()
+
+		if _, err := l.writeSink(sink, buf.Bytes()); err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+const (
+	maxPooledBuffer = 64 << 10
+)
+
+var (
+	bufferPool = sync.Pool{
+		New: func() any {
+			return bytes.NewBuffer(make([]byte, 0, 1024))
+		},
+	}
+)
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return buf
+}
+
+// putBuffer puts buf back to pool, large buffers are dropped for saving memory.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	bufferPool.Put(buf)
+}
+
+// record keeps the log by flight recorder or scope only.
+// NOTE: It must be called with the same depth of output for caller resolving.
+func (l *Logger) record(level Level, as *attrs, msg string) {
+	recorder := l.recorder()
+	if recorder == nil && l.scope == nil {
+		return
+	}
+
+	l.mux.RLock()
+	flag, skip, tags := l.flag, l.skip, *l.tags.Load()
+	l.mux.RUnlock()
+
+	file, line := l.caller(flag, skip)
+
+	r := l.newRecord(level, as, msg, tags, file, line)
+	defer freeRecord(r)
+
+	if !l.fire(r) || l.rules.dropped(r) {
+		return
+	}
+
+	l.tree.limits.Load().truncate(r)
+
+	if l.scope != nil {
+		if evicted, ok := l.scope.add(r, false); ok {
+			if evicted.enabled {
+				_ = l.emit(&evicted.Record, false)
+			}
+
+			return
+		}
+	}
+
+	if recorder != nil {
+		recorder.add(r)
+	}
+}
+
+// log writes msg of level if it's enabled, or keeps it by flight recorder or scope.
+func (l *Logger) log(level Level, msg string) {
+	if !l.tree.sampler.Load().sample(level, msg) {
+		return
+	}
+
+	if l.minLevel() > level {
+		l.record(level, nil, msg)
+		return
+	}
+
+	_ = l.output(level, nil, msg)
+}
+
+// logf is the same as log with sampling keyed by format.
+func (l *Logger) logf(level Level, format string, v ...any) {
+	if !l.tree.sampler.Load().sample(level, format) {
+		return
+	}
+
+	msg := fmt.Sprintf(format, v...)
+	if l.minLevel() > level {
+		l.record(level, nil, msg)
+		return
+	}
+
+	_ = l.output(level, nil, msg)
+}
+
+// accept returns whether a log of level should be formatted.
+func (l *Logger) accept(level Level) bool {
+	return l.Enabled(level) || l.scope != nil || l.recorder() != nil
+}
+
+// minLevel returns the effective min level of output, it's overridden by levels
+// of tags and lowered by escalation for a while after error spikes.
+func (l *Logger) minLevel() Level {
+	level := l.level.Load().Level()
+	tags := *l.tags.Load()
+
+	if tagged, ok := l.rules.tagLevel(tags); ok {
+		level = tagged
+	}
+
+	if esc := l.tree.escalation.Load(); esc != nil {
+		if escalated, ok := esc.escalated(); ok && escalated < level {
+			return escalated
+		}
+	}
+
+	return level
+}
+
+// caller returns source position of the log with flag and skip of the logger.
+// NOTE: It must be called by output or record directly for resolving call site.
+func (l *Logger) caller(flag, skip int) (file string, line int) {
+	if lim := l.tree.limiter.Load(); flag&(log.Lshortfile|log.Llongfile) == 0 && (lim == nil || !lim.byCaller) {
+		return
+	}
+
+	// the same as runtime.Caller(skip + 1) without allocations
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) < 1 {
+		return "???", 0
+	}
+
+	site := resolveCallsite(pcs[0])
+
+	return site.file, site.line
+}
+
+// callsite is a source position resolved from pc.
+type callsite struct {
+	file string
+	line int
+}
+
+var (
+	callsitesMux sync.RWMutex
+	callsites    = make(map[uintptr]callsite)
+)
+
+// resolveCallsite returns source position of pc, it's cached for pc is stable.
+func resolveCallsite(pc uintptr) callsite {
+	callsitesMux.RLock()
+	site, ok := callsites[pc]
+	callsitesMux.RUnlock()
+	if ok {
+		return site
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	site = callsite{
+		file: frame.File,
+		line: frame.Line,
+	}
+	if site.file == "" {
+		site.file = "???"
+	}
+
+	callsitesMux.Lock()
+	callsites[pc] = site
+	callsitesMux.Unlock()
+
+	return site
+}
+
+func (l *Logger) format(buf *bytes.Buffer, r *Record, colorful bool) {
+	var colorDraw, colorClean string
+	if colorful {
+		colour := colours[r.Level]
+		colorDraw, colorClean = colour[0], colour[1]
+	}
+
+	buf.WriteString(colorDraw)
+
+	l.formatHeader(buf, r)
+
+	if len(r.Fields) > 0 {
+		switch r.format {
 		case TextFormat:
-			l.buf.WriteString(as.String())
-			l.buf.WriteString(", ")
-			l.buf.WriteString("msg=")
+			writeTextAttrs(buf, r.Fields)
+			buf.WriteString(", ")
+			buf.WriteString("msg=")
 		case JSONFormat:
-			l.buf.WriteString(as.JSONString())
-			l.buf.WriteString(" ")
+			enc := jsonEncoder{buf: buf}
+			enc.encodeAttrs(r.Fields, 0)
+			buf.WriteString(" ")
 		}
 	}
-	l.buf.WriteString(msg)
+	if l.escape == EscapeNone {
+		buf.WriteString(r.Message)
+	} else {
+		escapeText(buf, strings.TrimSuffix(r.Message, "\n"), l.escape)
+	}
 
 	// adjust newline if it needs
-	if len(msg) > 0 && msg[len(msg)-1] != '\n' {
-		l.buf.WriteByte('\n')
+	if len(r.Message) > 0 && (l.escape != EscapeNone || r.Message[len(r.Message)-1] != '\n') {
+		buf.WriteByte('\n')
 	}
 
-	l.buf.WriteString(colorClean)
+	buf.WriteString(colorClean)
 
-	if as != nil && len(as.stacks) > 0 {
-		l.buf.Write(as.stacks)
-		l.buf.WriteByte('\n')
+	if len(r.stacks) > 0 {
+		buf.Write(r.stacks)
+		buf.WriteByte('\n')
 	}
-
-	_, err := l.buf.WriteTo(l.out)
-	return err
 }
 
-// Write implements io.Writer interface
+// Write implements io.Writer interface, b is written to sinks if they're set.
 func (l *Logger) Write(b []byte) (int, error) {
-	return l.out.Write(b)
+	sinks := l.Sinks()
+	if len(sinks) == 0 {
+		l.mux.RLock()
+		out := l.out
+		l.mux.RUnlock()
+
+		return l.writer.write(out, b)
+	}
+
+	var errs []error
+	for _, sink := range sinks {
+		if _, err := l.writeSink(sink, b); err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name, err))
+		}
+	}
+
+	return len(b), errors.Join(errs...)
+}
+
+// writeSink writes b to the sink, it's serialized with writes of the logger tree
+// for sinks may share writers with outputs, e.g. os.Stdout.
+func (l *Logger) writeSink(sink *Sink, b []byte) (int, error) {
+	sink.mux.Lock()
+	defer sink.mux.Unlock()
+
+	return l.writer.write(sink.Writer, b)
 }
 
 // Print calls l.Output to print to the logger.
@@ -320,109 +715,113 @@ func (l *Logger) Printf(format string, v ...any) {
 // Debug calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Debug(v ...any) {
-	if l.level > Ldebug {
+	if !l.accept(Ldebug) {
 		return
 	}
 
-	l.Output(Ldebug, fmt.Sprint(v...))
+	l.log(Ldebug, fmt.Sprint(v...))
 }
 
 // Debugf calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Debugf(format string, v ...any) {
-	if l.level > Ldebug {
+	if !l.accept(Ldebug) {
 		return
 	}
 
-	l.Output(Ldebug, fmt.Sprintf(format, v...))
+	l.logf(Ldebug, format, v...)
 }
 
 // Info calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Info(v ...any) {
-	if l.level > Linfo {
+	if !l.accept(Linfo) {
 		return
 	}
 
-	l.Output(Linfo, fmt.Sprint(v...))
+	l.log(Linfo, fmt.Sprint(v...))
 }
 
 // Infof calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Infof(format string, v ...any) {
-	if l.level > Linfo {
+	if !l.accept(Linfo) {
 		return
 	}
 
-	l.Output(Linfo, fmt.Sprintf(format, v...))
+	l.logf(Linfo, format, v...)
 }
 
 // Warn calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Warn(v ...any) {
-	if l.level > Lwarn {
+	if !l.accept(Lwarn) {
 		return
 	}
 
-	l.Output(Lwarn, fmt.Sprint(v...))
+	l.log(Lwarn, fmt.Sprint(v...))
 }
 
 // Warnf calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Warnf(format string, v ...any) {
-	if l.level > Lwarn {
+	if !l.accept(Lwarn) {
 		return
 	}
 
-	l.Output(Lwarn, fmt.Sprintf(format, v...))
+	l.logf(Lwarn, format, v...)
 }
 
 // Error calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Error(v ...any) {
-	if l.level > Lerror {
+	if !l.accept(Lerror) {
 		return
 	}
 
-	l.Output(Lerror, fmt.Sprint(v...))
+	l.log(Lerror, fmt.Sprint(v...))
 }
 
 // Errorf calls l.Output to print to the logger.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Errorf(format string, v ...any) {
-	if l.level > Lerror {
+	if !l.accept(Lerror) {
 		return
 	}
 
-	l.Output(Lerror, fmt.Sprintf(format, v...))
+	l.logf(Lerror, format, v...)
 }
 
 // Fatal calls l.Output to print to the logger and exit process with sign 1.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Fatal(v ...any) {
-	if l.level > Lfatal {
+	if l.Level() > Lfatal {
 		return
 	}
 
-	l.Output(Lfatal, fmt.Sprint(v...))
+	s := fmt.Sprint(v...)
+	l.Output(Lfatal, s)
+	l.crashed(s, nil)
 	os.Exit(1)
 }
 
 // Fatalf calls l.Output to print to the logger and exit process with sign 1.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Fatalf(format string, v ...any) {
-	if l.level > Lfatal {
+	if l.Level() > Lfatal {
 		return
 	}
 
-	l.Output(Lfatal, fmt.Sprintf(format, v...))
+	s := fmt.Sprintf(format, v...)
+	l.Output(Lfatal, s)
+	l.crashed(s, nil)
 	os.Exit(1)
 }
 
 // Panic calls l.Output to print to the logger and panic process.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Panic(v ...any) {
-	if l.level > Lpanic {
+	if l.Level() > Lpanic {
 		return
 	}
 
@@ -434,7 +833,7 @@ func (l *Logger) Panic(v ...any) {
 // Panicf calls l.Output to print to the logger and panic process.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Panicf(format string, v ...any) {
-	if l.level > Lpanic {
+	if l.Level() > Lpanic {
 		return
 	}
 
@@ -447,97 +846,106 @@ func (l *Logger) Panicf(format string, v ...any) {
 // and exit process with sign 1 at last.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Trace(v ...any) {
-	l.Output(Ltrace, fmt.Sprint(v...))
-
-	// avoid data race
-	l.mux.Lock()
-	defer l.mux.Unlock()
+	s := fmt.Sprint(v...)
+	l.Output(Ltrace, s)
+	l.crashed(s, nil)
 
 	// process stacks
 	buf := make([]byte, 1<<20)
 	n := runtime.Stack(buf, true)
 
+	var stacks bytes.Buffer
+
 	scanner := bufio.NewScanner(bytes.NewReader(buf[:n]))
 	for scanner.Scan() {
-		l.buf.WriteString(scanner.Text())
-		l.buf.WriteByte('\n')
+		stacks.WriteString(scanner.Text())
+		stacks.WriteByte('\n')
 	}
 
-	l.buf.WriteTo(l.out)
+	l.Write(stacks.Bytes())
 
 	os.Exit(1)
 }
 
 // Modified from src/log/log.go
-func (l *Logger) formatHeader(level Level, file string, line int) {
-	t := time.Now()
+func (l *Logger) formatHeader(buf *bytes.Buffer, r *Record) {
+	t := r.Time
 
 	if l.flag&(log.Ldate|log.Ltime|log.Lmicroseconds) != 0 {
 		if l.flag&log.Ldate != 0 {
 			year, month, day := t.Date()
 
-			itoa(l.buf, year, 4)
-			l.buf.WriteByte('/')
+			itoa(buf, year, 4)
+			buf.WriteByte('/')
 
-			itoa(l.buf, int(month), 2)
-			l.buf.WriteByte('/')
+			itoa(buf, int(month), 2)
+			buf.WriteByte('/')
 
-			itoa(l.buf, day, 2)
+			itoa(buf, day, 2)
 		}
 
 		if l.flag&(log.Ltime|log.Lmicroseconds) != 0 {
-			l.buf.WriteByte(' ')
+			buf.WriteByte(' ')
 
 			hour, minute, sec := t.Clock()
 
-			itoa(l.buf, hour, 2)
-			l.buf.WriteByte(':')
+			itoa(buf, hour, 2)
+			buf.WriteByte(':')
 
-			itoa(l.buf, minute, 2)
-			l.buf.WriteByte(':')
+			itoa(buf, minute, 2)
+			buf.WriteByte(':')
 
-			itoa(l.buf, sec, 2)
+			itoa(buf, sec, 2)
 			if l.flag&log.Lmicroseconds != 0 {
-				l.buf.WriteByte('.')
-				itoa(l.buf, t.Nanosecond()/1e3, 6)
+				buf.WriteByte('.')
+				itoa(buf, t.Nanosecond()/1e3, 6)
 			}
 		}
 
-		l.buf.WriteString(" - ")
+		buf.WriteString(" - ")
 	}
 
-	l.buf.WriteByte('[')
-	l.buf.WriteString(level.String())
-	for _, tag := range l.tags {
-		l.buf.WriteString(", ")
-		l.buf.WriteString(tag)
+	buf.WriteByte('[')
+	buf.WriteString(r.Level.String())
+	for _, tag := range r.Tags {
+		buf.WriteString(", ")
+		if l.escape == EscapeNone {
+			buf.WriteString(tag)
+		} else {
+			escapeText(buf, tag, EscapeAll)
+		}
 	}
-	l.buf.WriteByte(']')
-	l.buf.WriteString(" - ")
+	buf.WriteByte(']')
+	buf.WriteString(" - ")
 
 	if l.flag&(log.Lshortfile|log.Llongfile) != 0 {
-		short := file
-		if l.flag&log.Lshortfile != 0 {
-			for i := len(file) - 1; i > 0; i-- {
-				if file[i] == '/' {
-					short = file[i+1:]
-					break
-				}
+		buf.WriteString(l.shortFile(r.File))
+		buf.WriteByte(':')
+		itoa(buf, r.Line, -1)
+		buf.WriteString(": ")
+	}
+}
+
+// shortFile returns file path formatted by flag.
+func (l *Logger) shortFile(file string) string {
+	short := file
+	if l.flag&log.Lshortfile != 0 {
+		for i := len(file) - 1; i > 0; i-- {
+			if file[i] == '/' {
+				short = file[i+1:]
+				break
 			}
-		} else {
-			for i := 0; i < len(file)-5; i++ {
-				if file[i:i+5] == "/src/" {
-					short = file[i+1:]
-					break
-				}
+		}
+	} else {
+		for i := 0; i < len(file)-5; i++ {
+			if file[i:i+5] == "/src/" {
+				short = file[i+1:]
+				break
 			}
 		}
-
-		l.buf.WriteString(short)
-		l.buf.WriteByte(':')
-		itoa(l.buf, line, -1)
-		l.buf.WriteString(": ")
 	}
+
+	return short
 }
 
 // Cheap integer to fixed-width decimal ASCII.
@@ -1,12 +1,16 @@
 package logger
 
 import (
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golib/assert"
 )
@@ -232,3 +236,170 @@ func Test_Logger_Racy(t *testing.T) {
 
 	wg.Wait()
 }
+
+func Test_Logger_SettersRacy(t *testing.T) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+
+	child := logger.New("child")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				logger.Info("racy")
+				child.Error("racy")
+				child.NewJsonLogger().Str("key", "value").Debug("racy")
+				child.Enabled(Ldebug)
+				child.Escalated()
+
+				runtime.Gosched()
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				logger.SetFlag(log.Lshortfile)
+				child.SetSkip(2)
+				child.SetColor(j%2 == 0)
+				child.SetEscape(EscapeIndent)
+				child.SetTags("child", "racy")
+				logger.SetOutput(io.Discard)
+				logger.SetFlightRecorder(j % 8)
+				child.SetDedup(time.Duration(j%2) * time.Millisecond)
+				logger.SetSampling(j%3, 2, time.Millisecond)
+				child.SetRateLimit(&RateLimit{By: RateByCaller, Rate: 1000, Burst: 100})
+				logger.SetLimits(&Limits{MaxMessage: 64})
+				logger.SetEscalation(j%4, time.Second, time.Millisecond, Ldebug)
+				child.SetSinks(&Sink{Name: "discard", Writer: io.Discard})
+				logger.AddHook(HookFunc(func(r *Record) bool { return true }))
+				logger.SetCrashReport(nil)
+
+				runtime.Gosched()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	logger.SetEscalation(0, 0, 0, Ldebug)
+	logger.SetDedup(0)
+}
+
+// lineWriter records lines and whether writes overlapped.
+type lineWriter struct {
+	mux     sync.Mutex
+	writing int32
+	overlap atomic.Bool
+	lines   []string
+	block   chan struct{}
+}
+
+func (w *lineWriter) Write(b []byte) (int, error) {
+	if w.block != nil {
+		<-w.block
+	}
+
+	if atomic.AddInt32(&w.writing, 1) > 1 {
+		w.overlap.Store(true)
+	}
+	defer atomic.AddInt32(&w.writing, -1)
+
+	// gives other writers a chance to overlap
+	runtime.Gosched()
+
+	w.mux.Lock()
+	w.lines = append(w.lines, string(b))
+	w.mux.Unlock()
+
+	return len(b), nil
+}
+
+func Test_Logger_ConcurrentWrites(t *testing.T) {
+	var w lineWriter
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&w)
+	logger.SetColor(false)
+	logger.SetFlag(0)
+
+	var (
+		wg sync.WaitGroup
+
+		routines = 10
+		logs     = 100
+	)
+	wg.Add(routines)
+
+	for i := 0; i < routines; i++ {
+		go func(routine int) {
+			defer wg.Done()
+
+			for n := 0; n < logs; n++ {
+				logger.NewTextLogger().Int("routine", routine).Int("n", n).Info("concurrent")
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.False(t, w.overlap.Load())
+	assert.Equal(t, routines*logs, len(w.lines))
+
+	// each write is a whole line, and logs of a goroutine are in order
+	next := make(map[int]int)
+	for _, line := range w.lines {
+		assert.Equal(t, 1, strings.Count(line, "\n"))
+
+		var routine, n int
+		_, err := fmt.Sscanf(line, "[INFO] - routine=%d, n=%d, msg=concurrent\n", &routine, &n)
+		assert.Nil(t, err)
+		assert.Equal(t, next[routine], n)
+
+		next[routine] = n + 1
+	}
+}
+
+func Test_Logger_SlowWriter(t *testing.T) {
+	w := lineWriter{
+		block: make(chan struct{}),
+	}
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&w)
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info("blocked")
+		close(done)
+	}()
+
+	// setters are not blocked by a slow writer
+	time.Sleep(10 * time.Millisecond)
+	logger.SetTags("slow")
+	assert.Nil(t, logger.SetLevel(Linfo))
+
+	close(w.block)
+	<-done
+
+	assert.Equal(t, 1, len(w.lines))
+}
+
+func Benchmark_Logger_Parallel(b *testing.B) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.NewJsonLogger().Str("method", "GET").Int("status", 200).Info("request served")
+		}
+	})
+}
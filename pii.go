@@ -0,0 +1,355 @@
+package logger
+
+import (
+	"log/slog"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	ipv4Pattern   = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)
+	ipv6Pattern   = regexp.MustCompile(`[0-9A-Fa-f:]*:[0-9A-Fa-f:]*:[0-9A-Fa-f:.]*`)
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+
+	_ Hook = (*Scanner)(nil)
+)
+
+// Detector finds and masks a kind of PII in strings.
+type Detector struct {
+	// Name of detector.
+	Name string
+
+	// Hint is a cheap check of whether the string may contain PII,
+	// Mask is skipped if it returns false. A nil Hint always checks.
+	Hint func(s string) bool
+
+	// Mask returns s with PII masked.
+	Mask func(s string) string
+}
+
+// DetectEmail masks email addresses as [EMAIL].
+func DetectEmail() Detector {
+	return Detector{
+		Name: "email",
+		Hint: func(s string) bool {
+			return strings.IndexByte(s, '@') >= 0
+		},
+		Mask: func(s string) string {
+			return emailPattern.ReplaceAllLiteralString(s, "[EMAIL]")
+		},
+	}
+}
+
+// DetectCard masks Luhn checked card numbers of 13 to 19 digits,
+// which may be separated by spaces or dashes, as [CARD].
+func DetectCard() Detector {
+	return Detector{
+		Name: "card",
+		Hint: func(s string) bool {
+			digits := 0
+			for i := 0; i < len(s); i++ {
+				if isDigit(s[i]) {
+					digits++
+					if digits >= 13 {
+						return true
+					}
+				}
+			}
+
+			return false
+		},
+		Mask: maskCards,
+	}
+}
+
+// DetectIP masks IPv4 and IPv6 addresses as [IP].
+func DetectIP() Detector {
+	return Detector{
+		Name: "ip",
+		Hint: func(s string) bool {
+			return strings.Count(s, ".") >= 3 || strings.Count(s, ":") >= 2
+		},
+		Mask: func(s string) string {
+			if strings.Count(s, ".") >= 3 {
+				s = replaceMatches(s, ipv4Pattern, "[IP]", func(s string, start, end int) bool {
+					// skips dotted numbers like versions
+					if start > 0 && s[start-1] == '.' {
+						return false
+					}
+
+					return end+1 >= len(s) || s[end] != '.' || !isDigit(s[end+1])
+				})
+			}
+
+			if strings.Count(s, ":") >= 2 {
+				s = ipv6Pattern.ReplaceAllStringFunc(s, func(candidate string) string {
+					addr, err := netip.ParseAddr(candidate)
+					if err != nil || !addr.Is6() {
+						return candidate
+					}
+
+					return "[IP]"
+				})
+			}
+
+			return s
+		},
+	}
+}
+
+// DetectToken masks bearer tokens and JWTs as [TOKEN].
+func DetectToken() Detector {
+	return Detector{
+		Name: "token",
+		Hint: func(s string) bool {
+			return strings.Contains(s, "eyJ") || containsFold(s, "bearer")
+		},
+		Mask: func(s string) string {
+			s = bearerPattern.ReplaceAllString(s, "$1 [TOKEN]")
+
+			return jwtPattern.ReplaceAllLiteralString(s, "[TOKEN]")
+		},
+	}
+}
+
+// DetectRegexp masks matches of re as [NAME] with name upper cased.
+func DetectRegexp(name string, re *regexp.Regexp) Detector {
+	mask := "[" + strings.ToUpper(name) + "]"
+
+	return Detector{
+		Name: name,
+		Mask: func(s string) string {
+			return re.ReplaceAllLiteralString(s, mask)
+		},
+	}
+}
+
+// Scanner is a Hook masks PII found by detectors in message and string fields.
+type Scanner struct {
+	detectors []Detector
+}
+
+// NewScanner creates a Scanner with detectors, built-in detectors are used
+// if none is given, e.g. l.AddHook(logger.NewScanner())
+func NewScanner(detectors ...Detector) *Scanner {
+	if len(detectors) == 0 {
+		detectors = []Detector{DetectToken(), DetectEmail(), DetectCard(), DetectIP()}
+	}
+
+	return &Scanner{
+		detectors: detectors,
+	}
+}
+
+// Mask returns s with PII masked, s is returned as is if no detector matches.
+func (sc *Scanner) Mask(s string) string {
+	for _, detector := range sc.detectors {
+		if detector.Hint != nil && !detector.Hint(s) {
+			continue
+		}
+
+		s = detector.Mask(s)
+	}
+
+	return s
+}
+
+// Fire implements Hook interface.
+func (sc *Scanner) Fire(r *Record) bool {
+	r.Message = sc.Mask(r.Message)
+
+	var fields []slog.Attr
+	for i, attr := range r.Fields {
+		if attr.Value.Kind() != slog.KindString {
+			continue
+		}
+
+		value := attr.Value.String()
+
+		masked := sc.Mask(value)
+		if masked == value {
+			continue
+		}
+
+		if fields == nil {
+			fields = append([]slog.Attr(nil), r.Fields...)
+		}
+		fields[i] = slog.String(attr.Key, masked)
+	}
+
+	if fields != nil {
+		r.Fields = fields
+	}
+
+	return true
+}
+
+// replaceMatches replaces matches of re accepted with mask.
+func replaceMatches(s string, re *regexp.Regexp, mask string, accept func(s string, start, end int) bool) string {
+	var (
+		b    strings.Builder
+		last int
+	)
+
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		if !accept(s, loc[0], loc[1]) {
+			continue
+		}
+
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(mask)
+		last = loc[1]
+	}
+
+	if last == 0 {
+		return s
+	}
+
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// maskCards masks runs of 13 to 19 digits passed Luhn check. Digits separated by
+// spaces or dashes are tried in windows aligned to their groups, e.g. the card of
+// "4111 1111 1111 1111 2 times" is masked without the trailing 2.
+func maskCards(s string) string {
+	var (
+		b    strings.Builder
+		last int
+
+		// groups of a run are kept on stack mostly
+		stack  [8][2]int
+		groups = stack[:0]
+	)
+
+	for i := 0; i < len(s); {
+		if !isDigit(s[i]) || (i > 0 && isAlnum(s[i-1])) {
+			i++
+			continue
+		}
+
+		// groups of digits joined by single separators
+		groups = groups[:0]
+		for j := i; j < len(s); {
+			start := j
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			groups = append(groups, [2]int{start, j})
+
+			if j+1 < len(s) && (s[j] == ' ' || s[j] == '-') && isDigit(s[j+1]) {
+				j++
+				continue
+			}
+
+			break
+		}
+
+		end := groups[len(groups)-1][1]
+
+		for first := 0; first < len(groups); {
+			matched := -1
+			for n := len(groups) - 1; n >= first; n-- {
+				start, stop := groups[first][0], groups[n][1]
+				if stop == end && end < len(s) && isAlnum(s[end]) {
+					continue
+				}
+
+				if digits := countDigits(s[start:stop]); digits >= 13 && digits <= 19 && luhn(s[start:stop]) {
+					matched = n
+					break
+				}
+			}
+
+			if matched < 0 {
+				first++
+				continue
+			}
+
+			b.WriteString(s[last:groups[first][0]])
+			b.WriteString("[CARD]")
+			last = groups[matched][1]
+
+			first = matched + 1
+		}
+
+		i = end
+	}
+
+	if last == 0 {
+		return s
+	}
+
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// countDigits returns the number of digits of s.
+func countDigits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// luhn checks digits of s, non-digits are skipped.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if !isDigit(s[i]) {
+			continue
+		}
+
+		n := int(s[i] - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+
+		sum += n
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// containsFold reports whether s contains lower cased ASCII substr case insensitively.
+func containsFold(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		matched := true
+		for j := 0; j < len(substr); j++ {
+			c := s[i+j]
+			if c >= 'A' && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+
+			if c != substr[j] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
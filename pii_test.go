@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Scanner_Mask(t *testing.T) {
+	sc := NewScanner()
+
+	testCases := map[string]string{
+		"no pii here":                                   "no pii here",
+		"contact alice@example.com now":                 "contact [EMAIL] now",
+		"card 4111 1111 1111 1111 charged":              "card [CARD] charged",
+		"card 4111-1111-1111-1111":                      "card [CARD]",
+		"order 4111111111111112 is not a card":          "order 4111111111111112 is not a card",
+		"card 4111 1111 1111 1111 2 times":              "card [CARD] 2 times",
+		"order 12 4111-1111-1111-1111":                  "order 12 [CARD]",
+		"cards 4111111111111111 4111111111111111":       "cards [CARD] [CARD]",
+		"id 4111111111111111x":                          "id 4111111111111111x",
+		"from 192.168.1.10 to 10.0.0.1":                 "from [IP] to [IP]",
+		"from 2001:db8::1 at 12:30:45":                  "from [IP] at 12:30:45",
+		"version 1.2.3.4.5":                             "version 1.2.3.4.5",
+		"Authorization: Bearer abc.def-123":             "Authorization: Bearer [TOKEN]",
+		"jwt eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig_": "jwt [TOKEN]",
+	}
+
+	for s, expected := range testCases {
+		assert.Equal(t, expected, sc.Mask(s), s)
+	}
+}
+
+func Test_Scanner_MaskWithRegexp(t *testing.T) {
+	sc := NewScanner(DetectRegexp("ssn", regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)))
+
+	assert.Equal(t, "ssn [SSN]", sc.Mask("ssn 078-05-1120"))
+	assert.Equal(t, "alice@example.com", sc.Mask("alice@example.com"))
+}
+
+func Test_Logger_Scanner(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.AddHook(NewScanner())
+
+	logger.Infof("user %s logged in from %s", "alice@example.com", "10.0.0.1")
+	logger.NewJsonLogger().Str("email", "bob@example.com").Bool("ok", true).Info("signed up")
+
+	assert.Contains(t, buf.String(), "user [EMAIL] logged in from [IP]")
+	assert.Contains(t, buf.String(), `{"email":"[EMAIL]","ok":true} signed up`)
+}
+
+func Test_Scanner_MaskWithoutAllocs(t *testing.T) {
+	sc := NewScanner()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		sc.Mask("GET /api/v1/users/42 200 OK in 12ms")
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func Benchmark_Scanner_MaskWithoutMatch(b *testing.B) {
+	sc := NewScanner()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sc.Mask("GET /api/v1/users/42 200 OK in 12ms")
+	}
+}
+
+func Benchmark_Scanner_MaskWithMatch(b *testing.B) {
+	sc := NewScanner()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sc.Mask("user alice@example.com logged in from 10.0.0.1")
+	}
+}
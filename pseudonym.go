@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+const (
+	// PseudonymMask is written for pseudonym fields without Pseudonymizer.
+	PseudonymMask = "[PSEUDONYM]"
+
+	pseudonymSize = 16
+)
+
+var (
+	_ Hook = (*Pseudonymizer)(nil)
+)
+
+// Pseudonym is shortcut for pseudonym field option, the value is replaced with
+// a stable HMAC based pseudonym by Pseudonymizer, or masked without it.
+func Pseudonym(key, value string) Attr {
+	return Attr(slog.Any(key, pseudonymValue(value)))
+}
+
+// pseudonymValue never prints its raw value.
+type pseudonymValue string
+
+func (pv pseudonymValue) String() string {
+	return PseudonymMask
+}
+
+func (pv pseudonymValue) MarshalText() ([]byte, error) {
+	return []byte(PseudonymMask), nil
+}
+
+// Pseudonymizer is a Hook replaces values of pseudonym fields, and fields whose
+// keys match patterns, with pseudonyms of "<key id>:<hex of HMAC-SHA256>".
+// Patterns are case insensitive and support * as wildcard.
+type Pseudonymizer struct {
+	mux sync.RWMutex
+
+	keyID    string
+	secret   []byte
+	patterns []string
+}
+
+// NewPseudonymizer creates a Pseudonymizer with secret identified by keyID, e.g.
+// l.AddHook(logger.NewPseudonymizer("k1", secret, "user_id", "*email*"))
+func NewPseudonymizer(keyID string, secret []byte, patterns ...string) *Pseudonymizer {
+	p := &Pseudonymizer{
+		keyID:  keyID,
+		secret: secret,
+	}
+	for _, pattern := range patterns {
+		p.patterns = append(p.patterns, strings.ToLower(pattern))
+	}
+
+	return p
+}
+
+// Rotate replaces secret of the Pseudonymizer, pseudonyms after it are prefixed
+// with the new keyID.
+func (p *Pseudonymizer) Rotate(keyID string, secret []byte) {
+	p.mux.Lock()
+	p.keyID = keyID
+	p.secret = secret
+	p.mux.Unlock()
+}
+
+// Pseudonymize returns pseudonym of value with current secret.
+func (p *Pseudonymizer) Pseudonymize(value string) string {
+	p.mux.RLock()
+	keyID, secret := p.keyID, p.secret
+	p.mux.RUnlock()
+
+	return keyID + ":" + pseudonymize(secret, value)
+}
+
+// Match returns whether key matches any of patterns.
+func (p *Pseudonymizer) Match(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, pattern := range p.patterns {
+		if matchWildcard(pattern, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fire implements Hook interface.
+func (p *Pseudonymizer) Fire(r *Record) bool {
+	var fields []slog.Attr
+	for i, attr := range r.Fields {
+		var value string
+
+		if pv, ok := attr.Value.Any().(pseudonymValue); ok {
+			value = string(pv)
+		} else if p.Match(attr.Key) {
+			value = attr.Value.String()
+		} else {
+			continue
+		}
+
+		if fields == nil {
+			fields = append([]slog.Attr(nil), r.Fields...)
+		}
+		fields[i] = slog.String(attr.Key, p.Pseudonymize(value))
+	}
+
+	if fields != nil {
+		r.Fields = fields
+	}
+
+	return true
+}
+
+// VerifyPseudonym reports whether pseudonym is of value with secret, it's
+// useful for correlating a known value offline.
+func VerifyPseudonym(secret []byte, value, pseudonym string) bool {
+	i := strings.LastIndexByte(pseudonym, ':')
+	if i < 0 {
+		return false
+	}
+
+	return hmac.Equal([]byte(pseudonym[i+1:]), []byte(pseudonymize(secret, value)))
+}
+
+// PseudonymKeyID returns key id of pseudonym for looking up its secret.
+func PseudonymKeyID(pseudonym string) string {
+	i := strings.LastIndexByte(pseudonym, ':')
+	if i < 0 {
+		return ""
+	}
+
+	return pseudonym[:i]
+}
+
+func pseudonymize(secret []byte, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil)[:pseudonymSize])
+}
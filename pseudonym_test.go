@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Pseudonymizer(t *testing.T) {
+	p := NewPseudonymizer("k1", []byte("secret-1"), "user_id")
+
+	pseudonym := p.Pseudonymize("alice")
+	assert.True(t, strings.HasPrefix(pseudonym, "k1:"))
+	assert.Equal(t, pseudonym, p.Pseudonymize("alice"))
+	assert.NotEqual(t, pseudonym, p.Pseudonymize("bob"))
+
+	assert.Equal(t, "k1", PseudonymKeyID(pseudonym))
+	assert.True(t, VerifyPseudonym([]byte("secret-1"), "alice", pseudonym))
+	assert.False(t, VerifyPseudonym([]byte("secret-1"), "bob", pseudonym))
+	assert.False(t, VerifyPseudonym([]byte("secret-2"), "alice", pseudonym))
+
+	p.Rotate("k2", []byte("secret-2"))
+
+	rotated := p.Pseudonymize("alice")
+	assert.Equal(t, "k2", PseudonymKeyID(rotated))
+	assert.True(t, VerifyPseudonym([]byte("secret-2"), "alice", rotated))
+}
+
+func Test_Logger_Pseudonymizer(t *testing.T) {
+	var buf bytes.Buffer
+
+	p := NewPseudonymizer("k1", []byte("secret-1"), "user_id")
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.AddHook(p)
+
+	logger.NewTextLogger(Pseudonym("email", "alice@example.com")).Str("user_id", "u-42").Info("login")
+
+	assert.NotContains(t, buf.String(), "alice@example.com")
+	assert.NotContains(t, buf.String(), "u-42")
+	assert.Contains(t, buf.String(), "email="+p.Pseudonymize("alice@example.com"))
+	assert.Contains(t, buf.String(), "user_id="+p.Pseudonymize("u-42"))
+}
+
+func Test_Logger_PseudonymWithoutPseudonymizer(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	logger.NewTextLogger(Pseudonym("email", "alice@example.com")).Info("login")
+
+	assert.NotContains(t, buf.String(), "alice@example.com")
+	assert.Contains(t, buf.String(), "email="+PseudonymMask)
+}
@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RateByTag RateKey = iota
+	RateByCaller
+	RateByField
+)
+
+const (
+	defaultRateReport = time.Minute
+
+	// maxRateBuckets caps keys tracked by limiter, logs of new keys
+	// beyond it are passed without limiting until buckets are pruned.
+	maxRateBuckets = 4096
+)
+
+type (
+	// RateKey defines how logs are keyed for rate limiting.
+	RateKey int
+)
+
+// RateLimit defines token bucket rate limits of logs.
+type RateLimit struct {
+	// By is the key of buckets, logs with empty key are not limited.
+	By RateKey
+
+	// Field is the name of field for RateByField.
+	Field string
+
+	// Rate is the number of logs allowed per second of each key.
+	Rate float64
+
+	// Burst is the max number of logs allowed at once of each key, default to 1.
+	Burst int
+
+	// ExemptErrors skips limiting of error and more serious logs.
+	ExemptErrors bool
+
+	// Report is the interval of reporting counts of limited logs and pruning
+	// idle buckets, default to 1 minute.
+	Report time.Duration
+}
+
+// SetRateLimit limits rate of logs by tags, caller or field, a nil value disables it.
+// NOTE: The limit is shared by all loggers created with l.New().
+func (l *Logger) SetRateLimit(rl *RateLimit) {
+	var lim *limiter
+	if rl != nil {
+		lim = &limiter{
+			logger:   l,
+			limit:    *rl,
+			byCaller: rl.By == RateByCaller,
+			buckets:  make(map[string]*bucket),
+		}
+		if lim.limit.Burst < 1 {
+			lim.limit.Burst = 1
+		}
+		if lim.limit.Report <= 0 {
+			lim.limit.Report = defaultRateReport
+		}
+	}
+
+	l.tree.limiter.Store(lim)
+}
+
+type bucket struct {
+	tokens  float64
+	last    time.Time
+	limited int
+}
+
+type limiter struct {
+	mux sync.Mutex
+
+	logger    *Logger
+	limit     RateLimit
+	byCaller  bool
+	buckets   map[string]*bucket
+	reporting bool // whether report is scheduled
+}
+
+func (lim *limiter) key(r *Record) string {
+	switch lim.limit.By {
+	case RateByTag:
+		return strings.Join(r.Tags, ", ")
+
+	case RateByCaller:
+		if r.File == "" {
+			return ""
+		}
+
+		return r.File + ":" + strconv.Itoa(r.Line)
+
+	case RateByField:
+		for _, attr := range r.Fields {
+			if attr.Key == lim.limit.Field {
+				return attr.Key + "=" + attr.Value.String()
+			}
+		}
+	}
+
+	return ""
+}
+
+// allow returns whether the record should be written.
+func (lim *limiter) allow(r *Record) bool {
+	if lim.limit.ExemptErrors && r.Level >= Lerror && r.Level <= Ltrace {
+		return true
+	}
+
+	key := lim.key(r)
+	if key == "" {
+		return true
+	}
+
+	now := time.Now()
+
+	lim.mux.Lock()
+	defer lim.mux.Unlock()
+
+	b, ok := lim.buckets[key]
+	if !ok {
+		if len(lim.buckets) >= maxRateBuckets {
+			return true
+		}
+
+		b = &bucket{
+			tokens: float64(lim.limit.Burst),
+			last:   now,
+		}
+
+		lim.buckets[key] = b
+
+		// prunes buckets no matter logs are limited or not
+		lim.schedule()
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * lim.limit.Rate
+	if b.tokens > float64(lim.limit.Burst) {
+		b.tokens = float64(lim.limit.Burst)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+
+	b.limited++
+	lim.schedule()
+
+	return false
+}
+
+// schedule schedules report after interval, it must be called with lock held.
+func (lim *limiter) schedule() {
+	if lim.reporting {
+		return
+	}
+
+	lim.reporting = true
+
+	time.AfterFunc(lim.limit.Report, lim.report)
+}
+
+// report logs counts of limited logs and prunes idle buckets.
+func (lim *limiter) report() {
+	now := time.Now()
+
+	type report struct {
+		key     string
+		limited int
+	}
+
+	var reports []report
+
+	lim.mux.Lock()
+	for key, b := range lim.buckets {
+		if b.limited > 0 {
+			reports = append(reports, report{
+				key:     key,
+				limited: b.limited,
+			})
+
+			b.limited = 0
+		}
+
+		if now.Sub(b.last) > lim.limit.Report {
+			delete(lim.buckets, key)
+		}
+	}
+	lim.reporting = false
+	if len(lim.buckets) > 0 {
+		lim.schedule()
+	}
+	lim.mux.Unlock()
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].key < reports[j].key
+	})
+
+	// reports bypass limits of themselves
+	for _, r := range reports {
+		record := lim.logger.newRecord(Lwarn, nil, fmt.Sprintf("rate limited %d logs of %q", r.limited, r.key), lim.logger.Tags(), "", 0)
+		_ = lim.logger.write(record)
+
+		freeRecord(record)
+	}
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_RateLimitByTag(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetRateLimit(&RateLimit{
+		By:     RateByTag,
+		Rate:   0.001,
+		Burst:  2,
+		Report: 50 * time.Millisecond,
+	})
+
+	tenant := logger.New("tenant-a")
+	for i := 0; i < 5; i++ {
+		tenant.Info("flooding")
+	}
+	logger.New("tenant-b").Info("quiet")
+	logger.Info("untagged")
+
+	output := buf.String()
+	assert.Equal(t, 2, strings.Count(output, "flooding"))
+	assert.Contains(t, output, "quiet")
+	assert.Contains(t, output, "untagged")
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Contains(t, buf.String(), `rate limited 3 logs of "tenant-a"`)
+	assert.NotContains(t, buf.String(), "???")
+}
+
+func Test_Logger_RateLimitByField(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetRateLimit(&RateLimit{
+		By:           RateByField,
+		Field:        "client_ip",
+		Rate:         0.001,
+		Burst:        1,
+		ExemptErrors: true,
+	})
+
+	for i := 0; i < 3; i++ {
+		logger.NewTextLogger().Str("client_ip", "10.0.0.1").Info("request")
+		logger.NewTextLogger().Str("client_ip", "10.0.0.2").Info("request")
+	}
+	assert.Equal(t, 2, strings.Count(buf.String(), "msg=request"))
+
+	for i := 0; i < 3; i++ {
+		logger.NewTextLogger().Str("client_ip", "10.0.0.1").Error("failure")
+	}
+	assert.Equal(t, 3, strings.Count(buf.String(), "msg=failure"))
+}
+
+func Test_Logger_RateLimitByCaller(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetFlag(0)
+	logger.SetRateLimit(&RateLimit{
+		By:    RateByCaller,
+		Rate:  0.001,
+		Burst: 1,
+	})
+
+	for i := 0; i < 3; i++ {
+		logger.NewTextLogger().Bool("loop", true).Info("same caller")
+	}
+	logger.NewTextLogger().Bool("loop", false).Info("other caller")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "same caller"))
+	assert.Contains(t, buf.String(), "other caller")
+}
+
+func Test_Logger_RateLimitWithoutBurst(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetRateLimit(&RateLimit{
+		By:   RateByTag,
+		Rate: 10,
+	})
+
+	tenant := logger.New("tenant-a")
+	tenant.Info("first")
+	tenant.Info("second")
+
+	assert.Contains(t, buf.String(), "first")
+	assert.NotContains(t, buf.String(), "second")
+}
+
+func Test_Logger_RateLimitPrunesBuckets(t *testing.T) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+	logger.SetRateLimit(&RateLimit{
+		By:     RateByField,
+		Field:  "client_ip",
+		Rate:   10,
+		Burst:  10,
+		Report: 20 * time.Millisecond,
+	})
+
+	lim := logger.tree.limiter.Load()
+
+	// keys are never limited
+	for i := 0; i < 100; i++ {
+		logger.NewTextLogger().Str("client_ip", strconv.Itoa(i)).Info("request")
+	}
+
+	lim.mux.Lock()
+	assert.Equal(t, 100, len(lim.buckets))
+	lim.mux.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+
+	lim.mux.Lock()
+	assert.Equal(t, 0, len(lim.buckets))
+	lim.mux.Unlock()
+
+	// new keys beyond the cap are passed without limiting, buckets are not
+	// pruned while filling them
+	logger.SetRateLimit(&RateLimit{
+		By:     RateByField,
+		Field:  "client_ip",
+		Rate:   10,
+		Burst:  10,
+		Report: time.Hour,
+	})
+
+	lim = logger.tree.limiter.Load()
+	for i := 0; i < maxRateBuckets+10; i++ {
+		r := &Record{Level: Linfo, Fields: []slog.Attr{slog.Int("client_ip", i)}}
+		assert.True(t, lim.allow(r))
+	}
+
+	lim.mux.Lock()
+	assert.Equal(t, maxRateBuckets, len(lim.buckets))
+	lim.mux.Unlock()
+}
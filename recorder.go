@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// SetFlightRecorder keeps the last size logs below the level of Logger in memory,
+// and dumps them to the output when an error or more serious log is written.
+// Logs written already are not kept. A size of 0 disables it.
+// NOTE: The recorder is shared by all loggers created with l.New(), except scopes.
+func (l *Logger) SetFlightRecorder(size int) {
+	var recorder *flightRecorder
+	if size > 0 {
+		recorder = newFlightRecorder(size)
+	}
+
+	l.tree.recorder.Store(recorder)
+}
+
+// DumpFlightRecorder writes logs kept by flight recorder to the output.
+func (l *Logger) DumpFlightRecorder() error {
+	recorder := l.recorder()
+	if recorder == nil {
+		return nil
+	}
+
+	_, file, line, _ := runtime.Caller(1)
+
+	return recorder.dump(l, file, line)
+}
+
+// recorder returns flight recorder of the logger tree, logs of scopes are
+// kept by scopes instead.
+func (l *Logger) recorder() *flightRecorder {
+	if l.scope != nil {
+		return nil
+	}
+
+	return l.tree.recorder.Load()
+}
+
+type flightRecorder struct {
+	mux sync.Mutex
+
+	records []Record
+	next    int
+	full    bool
+}
+
+func newFlightRecorder(size int) *flightRecorder {
+	return &flightRecorder{
+		records: make([]Record, size),
+	}
+}
+
+func (fr *flightRecorder) add(r *Record) {
+	fr.mux.Lock()
+	fr.records[fr.next] = r.clone()
+	fr.next++
+	if fr.next == len(fr.records) {
+		fr.next = 0
+		fr.full = true
+	}
+	fr.mux.Unlock()
+}
+
+// dump writes all records in order between markers with caller given,
+// and resets the recorder.
+func (fr *flightRecorder) dump(l *Logger, file string, line int) error {
+	fr.mux.Lock()
+	var records []Record
+	if fr.full {
+		records = append(records, fr.records[fr.next:]...)
+	}
+	records = append(records, fr.records[:fr.next]...)
+
+	clear(fr.records)
+	fr.next = 0
+	fr.full = false
+	fr.mux.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	tags := l.Tags()
+
+	r := l.newRecord(Llog, nil, fmt.Sprintf("--- flight recorder: %d records ---", len(records)), tags, file, line)
+	err := l.emit(r, true)
+
+	freeRecord(r)
+	if err != nil {
+		return err
+	}
+
+	for i := range records {
+		if err := l.emit(&records[i], true); err != nil {
+			return err
+		}
+	}
+
+	r = l.newRecord(Llog, nil, "--- flight recorder: end ---", tags, file, line)
+	defer freeRecord(r)
+
+	return l.emit(r, true)
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_FlightRecorder(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetColor(false)
+	logger.SetLevel(Lwarn)
+	logger.SetFlightRecorder(2)
+
+	logger.Debug("debug 1")
+	logger.Debug("debug 2")
+	logger.Info("info 3")
+	assert.Empty(t, buf.String())
+
+	logger.Error("failed")
+
+	output := buf.String()
+	assert.Contains(t, output, "--- flight recorder: 2 records ---")
+	assert.NotContains(t, output, "debug 1")
+	assert.Contains(t, output, "debug 2")
+	assert.Contains(t, output, "info 3")
+	assert.Contains(t, output, "[ERROR] - ")
+	assert.True(t, strings.Index(output, "info 3") < strings.Index(output, "failed"))
+
+	// the recorder is reset after dumping
+	buf.Reset()
+	logger.Error("failed again")
+	assert.NotContains(t, buf.String(), "flight recorder")
+}
+
+func Test_Logger_FlightRecorderWithWritten(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Linfo)
+	logger.SetFlightRecorder(10)
+
+	logger.Info("already written")
+	logger.Debug("kept")
+	logger.Error("boom")
+
+	output := buf.String()
+	assert.Equal(t, 1, strings.Count(output, "already written"))
+	assert.Contains(t, output, "--- flight recorder: 1 records ---")
+	assert.Contains(t, output, "kept")
+}
+
+func Test_Logger_DumpFlightRecorder(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Linfo)
+	logger.SetFlightRecorder(10)
+
+	logger.New("child").Debug("debug from child")
+	assert.Empty(t, buf.String())
+
+	assert.Nil(t, logger.DumpFlightRecorder())
+	assert.Contains(t, buf.String(), "[DEBUG, child]")
+	assert.Contains(t, buf.String(), "--- flight recorder: end ---")
+}
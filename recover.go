@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+type (
+	// RecoverOption for panic recovery option
+	RecoverOption func(opts *recoverOptions)
+)
+
+type recoverOptions struct {
+	repanic  bool
+	callback func(v any, stack []byte)
+	attrs    []Attr
+}
+
+// Repanic re-panics with the recovered value after it has been logged,
+// a crash report is written before if it is enabled.
+func Repanic() RecoverOption {
+	return func(opts *recoverOptions) {
+		opts.repanic = true
+	}
+}
+
+// OnPanic registers a callback invoked with the recovered value and stack
+// after it has been logged.
+func OnPanic(fn func(v any, stack []byte)) RecoverOption {
+	return func(opts *recoverOptions) {
+		opts.callback = fn
+	}
+}
+
+// PanicFields appends fields to the panic log.
+func PanicFields(attrs ...Attr) RecoverOption {
+	return func(opts *recoverOptions) {
+		opts.attrs = append(opts.attrs, attrs...)
+	}
+}
+
+// Recover recovers a panic of current goroutine and logs it with stacks at Lpanic level.
+// NOTE: It MUST be called directly by defer, e.g. defer logger.Recover(log)
+func Recover(l *Logger, opts ...RecoverOption) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	l.recovered(v, opts...)
+}
+
+// Go runs fn in a new goroutine, panics of fn will be recovered and logged by l.
+func (l *Logger) Go(fn func(), opts ...RecoverOption) {
+	go func() {
+		defer Recover(l, opts...)
+
+		fn()
+	}()
+}
+
+func (l *Logger) recovered(v any, opts ...RecoverOption) {
+	var options recoverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	stack := debug.Stack()
+
+	as := &attrs{
+		format: TextFormat,
+		fields: appendAttrs(nil, options.attrs...),
+		stacks: stack,
+	}
+
+	msg := fmt.Sprintf("panic: %v", v)
+
+	// the log is reported at where it panicked instead of Recover
+	file, line := panicSite()
+
+	_ = l.outputAt(Lpanic, as, msg, l.Tags(), file, line)
+
+	if options.callback != nil {
+		options.callback(v, stack)
+	}
+
+	// panics recovered are not crashes unless they're re-panicked
+	if options.repanic {
+		l.crashed(msg, stack)
+
+		panic(v)
+	}
+}
+
+// panicSite returns source position of the panic recovering, it's the first
+// frame out of runtime after runtime.gopanic, e.g. runtime.panicmem.
+func panicSite() (file string, line int) {
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+
+	panicking := false
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame.File, frame.Line
+		}
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		}
+
+		if !more {
+			return "???", 0
+		}
+	}
+}
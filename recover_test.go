@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_Recover(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetColor(false)
+	logger.SetTags("worker")
+
+	assert.NotPanics(t, func() {
+		defer Recover(logger, PanicFields(String("job", "sync")))
+
+		panic("boom")
+	})
+	assert.Contains(t, buf.String(), "[PANIC, worker]")
+	assert.Contains(t, buf.String(), "job=sync,")
+	assert.Contains(t, buf.String(), "msg=panic: boom")
+	assert.Contains(t, buf.String(), "runtime/debug.Stack")
+}
+
+func Test_Logger_RecoverWithPanicSite(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetColor(false)
+	logger.SetFlag(log.Lshortfile)
+
+	var line int
+	assert.NotPanics(t, func() {
+		defer Recover(logger)
+
+		_, _, line, _ = runtime.Caller(0)
+		panic("boom")
+	})
+	assert.Contains(t, buf.String(), "[PANIC] - recover_test.go:"+strconv.Itoa(line+1)+": ")
+
+	// runtime errors are reported at where they occur
+	buf.Reset()
+	assert.NotPanics(t, func() {
+		defer Recover(logger)
+
+		var m map[string]int
+
+		_, _, line, _ = runtime.Caller(0)
+		m["key"] = 1
+	})
+	assert.Contains(t, buf.String(), "[PANIC] - recover_test.go:"+strconv.Itoa(line+1)+": ")
+}
+
+func Test_Logger_RecoverWithRepanic(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	assert.Panics(t, func() {
+		defer Recover(logger, Repanic())
+
+		panic("boom")
+	})
+	assert.Contains(t, buf.String(), "panic: boom")
+}
+
+func Test_Logger_Go(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		wg  sync.WaitGroup
+
+		recovered any
+	)
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	wg.Add(1)
+	logger.Go(func() {
+		panic("boom")
+	}, OnPanic(func(v any, stack []byte) {
+		defer wg.Done()
+
+		recovered = v
+	}))
+	wg.Wait()
+
+	assert.Equal(t, "boom", recovered)
+	assert.Contains(t, buf.String(), "[PANIC]")
+}
@@ -0,0 +1,291 @@
+package logger
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+const (
+	// RedactedMask replaces values redacted.
+	RedactedMask = "[REDACTED]"
+
+	maxRedactDepth = 8
+)
+
+var (
+	// RedactPatterns is a list of common sensitive keys.
+	RedactPatterns = []string{
+		"password",
+		"passwd",
+		"token",
+		"*_token",
+		"authorization",
+		"cookie",
+		"set-cookie",
+		"api_key",
+		"apikey",
+		"*secret*",
+	}
+
+	_ Hook = (*Redactor)(nil)
+)
+
+// Redacted is a marker interface of values which are always redacted.
+type Redacted interface {
+	Redacted()
+}
+
+// Redactor is a Hook masks values of fields whose keys match patterns, values
+// implementing Redacted, and nested keys of map, slice and struct values.
+// Fields of structs are matched by names of json tags, or names of fields.
+// Patterns are case insensitive and support * as wildcard.
+type Redactor struct {
+	patterns []string
+}
+
+// NewRedactor creates a Redactor with patterns, e.g.
+// l.AddHook(logger.NewRedactor(logger.RedactPatterns...))
+func NewRedactor(patterns ...string) *Redactor {
+	rd := &Redactor{}
+	for _, pattern := range patterns {
+		rd.patterns = append(rd.patterns, strings.ToLower(pattern))
+	}
+
+	return rd
+}
+
+// Match returns whether key matches any of patterns.
+func (rd *Redactor) Match(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, pattern := range rd.patterns {
+		if matchWildcard(pattern, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fire implements Hook interface.
+func (rd *Redactor) Fire(r *Record) bool {
+	if fields, ok := rd.redactAttrs(r.Fields); ok {
+		r.Fields = fields
+	}
+
+	return true
+}
+
+// redactAttrs returns a copy of attrs redacted if it's changed.
+func (rd *Redactor) redactAttrs(attrs []slog.Attr) ([]slog.Attr, bool) {
+	var redacted []slog.Attr
+	for i, attr := range attrs {
+		if value, ok := rd.redactAttr(attr); ok {
+			if redacted == nil {
+				redacted = append([]slog.Attr(nil), attrs...)
+			}
+
+			redacted[i] = value
+		}
+	}
+
+	if redacted == nil {
+		return attrs, false
+	}
+
+	return redacted, true
+}
+
+func (rd *Redactor) redactAttr(attr slog.Attr) (slog.Attr, bool) {
+	if rd.Match(attr.Key) {
+		return slog.String(attr.Key, RedactedMask), true
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		if group, ok := rd.redactAttrs(attr.Value.Group()); ok {
+			return slog.Attr{Key: attr.Key, Value: slog.GroupValue(group...)}, true
+		}
+
+	case slog.KindAny:
+		if value, ok := rd.redactValue(attr.Value.Any(), 0); ok {
+			return slog.Any(attr.Key, value), true
+		}
+	}
+
+	return attr, false
+}
+
+// redactValue returns a copy of value redacted if it's changed.
+func (rd *Redactor) redactValue(value any, depth int) (any, bool) {
+	if value == nil || depth > maxRedactDepth {
+		return value, false
+	}
+
+	if _, ok := value.(Redacted); ok {
+		return RedactedMask, true
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return value, false
+		}
+
+		rv = rv.Elem()
+		if _, ok := rv.Interface().(Redacted); ok {
+			return RedactedMask, true
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value, false
+		}
+
+		changed := false
+		redacted := make(map[string]any, rv.Len())
+
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if rd.Match(key) {
+				redacted[key] = RedactedMask
+				changed = true
+				continue
+			}
+
+			v, ok := rd.redactValue(iter.Value().Interface(), depth+1)
+			redacted[key] = v
+			changed = changed || ok
+		}
+
+		if changed {
+			return redacted, true
+		}
+
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return value, false
+		}
+
+		changed := false
+		redacted := make([]any, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			v, ok := rd.redactValue(rv.Index(i).Interface(), depth+1)
+			redacted[i] = v
+			changed = changed || ok
+		}
+
+		if changed {
+			return redacted, true
+		}
+
+	case reflect.Struct:
+		// values marshaled by themselves are kept as is
+		if rt := reflect.PointerTo(rv.Type()); rt.Implements(jsonMarshalerType) || rt.Implements(textMarshalerType) {
+			return value, false
+		}
+
+		redacted := make(map[string]any, rv.NumField())
+		if rd.redactFields(rv, depth+1, redacted) {
+			return redacted, true
+		}
+	}
+
+	return value, false
+}
+
+// redactFields puts exported fields of struct into redacted with names of json
+// tags, fields of embedded structs are promoted. It returns whether any field
+// is redacted.
+func (rd *Redactor) redactFields(rv reflect.Value, depth int, redacted map[string]any) bool {
+	if depth > maxRedactDepth {
+		return false
+	}
+
+	changed := false
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := rv.Field(i)
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && !ft.Implements(jsonMarshalerType) && !ft.Implements(textMarshalerType) {
+				changed = rd.redactFields(fv, depth+1, redacted) || changed
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(fv) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if rd.Match(name) || rd.Match(field.Name) {
+			redacted[name] = RedactedMask
+			changed = true
+			continue
+		}
+
+		// fields promoted from unexported structs are inaccessible
+		if !fv.CanInterface() {
+			continue
+		}
+
+		v, ok := rd.redactValue(fv.Interface(), depth+1)
+		redacted[name] = v
+		changed = changed || ok
+	}
+
+	return changed
+}
+
+// matchWildcard reports whether s matches pattern with * as wildcard.
+func matchWildcard(pattern, s string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == s
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+
+		s = s[i+len(part):]
+	}
+
+	return len(s) >= len(last) && strings.HasSuffix(s, last)
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+type apiKey string
+
+func (apiKey) Redacted() {}
+
+func Test_Redactor_Match(t *testing.T) {
+	rd := NewRedactor(RedactPatterns...)
+
+	for _, key := range []string{"password", "Authorization", "access_token", "client_secret", "SECRET", "mysecretvalue"} {
+		assert.True(t, rd.Match(key), key)
+	}
+	for _, key := range []string{"user", "tokens", "passwords", "content-type"} {
+		assert.False(t, rd.Match(key), key)
+	}
+}
+
+func Test_Redactor_Fire(t *testing.T) {
+	rd := NewRedactor(RedactPatterns...)
+
+	fields := []slog.Attr{
+		slog.String("user", "alice"),
+		slog.String("password", "p@ss"),
+		slog.Any("key", apiKey("k-123")),
+		slog.Any("headers", http.Header{"Authorization": {"Bearer xyz"}, "Accept": {"*/*"}}),
+		slog.Any("nested", []any{map[string]any{"token": "t-1", "id": 1}}),
+		slog.Group("db", slog.String("user", "root"), slog.String("password", "root")),
+	}
+	r := &Record{
+		Fields: fields,
+	}
+
+	assert.True(t, rd.Fire(r))
+	assert.Equal(t, "alice", r.Fields[0].Value.String())
+	assert.Equal(t, RedactedMask, r.Fields[1].Value.String())
+	assert.Equal(t, RedactedMask, r.Fields[2].Value.Any())
+	assert.Equal(t, map[string]any{"Authorization": RedactedMask, "Accept": []string{"*/*"}}, r.Fields[3].Value.Any())
+	assert.Equal(t, []any{map[string]any{"token": RedactedMask, "id": 1}}, r.Fields[4].Value.Any())
+	assert.Equal(t, RedactedMask, r.Fields[5].Value.Group()[1].Value.String())
+
+	// fields of caller are not modified
+	assert.Equal(t, "p@ss", fields[1].Value.String())
+}
+
+func Test_Logger_Redactor(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.AddHook(NewRedactor(RedactPatterns...))
+
+	logger.NewTextLogger().Fields(map[string]any{
+		"authorization": "Bearer xyz",
+	}).Info("text")
+	logger.NewJsonLogger().Str("api_key", "k-123").Str("user", "alice").Info("json")
+
+	assert.NotContains(t, buf.String(), "xyz")
+	assert.NotContains(t, buf.String(), "k-123")
+	assert.Contains(t, buf.String(), "authorization=[REDACTED], msg=text")
+	assert.Contains(t, buf.String(), `{"api_key":"[REDACTED]","user":"alice"} json`)
+}
+
+type redactUser struct {
+	Name     string `json:"name"`
+	Password string `json:"pwd"`
+	Token    *apiKey
+	Profile  *redactProfile `json:"profile,omitempty"`
+}
+
+type redactProfile struct {
+	Email  string
+	Secret any `json:"client_secret"`
+}
+
+func Test_Redactor_FireWithStruct(t *testing.T) {
+	rd := NewRedactor(RedactPatterns...)
+
+	key := apiKey("k-123")
+	user := &redactUser{
+		Name:     "alice",
+		Password: "p@ss",
+		Token:    &key,
+		Profile: &redactProfile{
+			Email:  "alice@example.com",
+			Secret: "s-1",
+		},
+	}
+	r := &Record{
+		Fields: []slog.Attr{
+			slog.Any("user", user),
+			slog.Any("contact", struct{ Email string }{"bob@example.com"}),
+		},
+	}
+
+	assert.True(t, rd.Fire(r))
+	assert.Equal(t, map[string]any{
+		"name":  "alice",
+		"pwd":   RedactedMask,
+		"Token": RedactedMask,
+		"profile": map[string]any{
+			"Email":         "alice@example.com",
+			"client_secret": RedactedMask,
+		},
+	}, r.Fields[0].Value.Any())
+
+	// structs without sensitive values are kept
+	assert.Equal(t, struct{ Email string }{"bob@example.com"}, r.Fields[1].Value.Any())
+
+	// values of caller are not modified
+	assert.Equal(t, "p@ss", user.Password)
+}
+
+func Test_Logger_RedactorWithStruct(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.AddHook(NewRedactor(RedactPatterns...))
+
+	logger.NewJsonLogger().Any("user", struct {
+		User     string
+		Password string
+	}{"alice", "p@ss"}).Info("json")
+
+	assert.NotContains(t, buf.String(), "p@ss")
+	assert.Contains(t, buf.String(), `{"user":{"Password":"[REDACTED]","User":"alice"}} json`)
+}
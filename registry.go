@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// Registry manages loggers by dotted names, e.g. db, db.pool and http.client.
+// A logger inherits level, output, format and sinks of its nearest parent name
+// configured, or the root logger if none of them is configured.
+type Registry struct {
+	mux  sync.RWMutex
+	root *Logger
+
+	loggers map[string]*Logger
+	levels  map[string]*LevelVar
+	outputs map[string]io.Writer
+	formats map[string]Formatter
+	sinks   map[string][]*Sink
+
+	// sinks of formats are shared by loggers with the same names of format
+	// and output resolved.
+	formatSinks map[formatKey]*Sink
+}
+
+type formatKey struct {
+	format, output string
+}
+
+// NewRegistry creates a registry of loggers derived from root.
+func NewRegistry(root *Logger) *Registry {
+	return &Registry{
+		root:    root,
+		loggers: map[string]*Logger{},
+		levels:  map[string]*LevelVar{},
+		outputs: map[string]io.Writer{},
+		formats: map[string]Formatter{},
+		sinks:   map[string][]*Sink{},
+
+		formatSinks: map[formatKey]*Sink{},
+	}
+}
+
+// Get returns the logger of name, it's created from the logger of its parent
+// name with tags of the name on first use. An empty name returns the root logger.
+func (r *Registry) Get(name string) *Logger {
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return r.root
+	}
+
+	r.mux.RLock()
+	l, ok := r.loggers[name]
+	r.mux.RUnlock()
+	if ok {
+		return l
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.get(name)
+}
+
+// Names returns names of all loggers created.
+func (r *Registry) Names() []string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	names := make([]string, 0, len(r.loggers))
+	for name := range r.loggers {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// SetLevel sets min level of output of name, it cascades to loggers of name.*
+// unless their levels are set explicitly. An empty name sets level of the root logger.
+func (r *Registry) SetLevel(name string, level Level) error {
+	if !level.IsValid() {
+		return ErrLevel
+	}
+
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return r.root.SetLevel(level)
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if lv, ok := r.levels[name]; ok {
+		return lv.Set(level)
+	}
+
+	r.levels[name] = NewLevelVar(level)
+	r.cascade(name)
+
+	return nil
+}
+
+// SetLevelByName sets min level of output of name by level name.
+// It returns ErrLevel for invalid level name.
+func (r *Registry) SetLevelByName(name, level string) error {
+	return r.SetLevel(name, ResolveLevelByName(level))
+}
+
+// SetOutput sets output of name, it cascades to loggers of name.*
+// unless their outputs are set explicitly. An empty name sets output of
+// the root logger and all loggers.
+func (r *Registry) SetOutput(name string, w io.Writer) {
+	name = strings.Trim(name, ".")
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.outputs[name] = w
+	clear(r.formatSinks)
+	r.cascade(name)
+}
+
+// SetFormat sets format of name, it cascades to loggers of name.*
+// unless their formats are set explicitly. An empty name sets format of
+// the root logger and all loggers.
+// NOTE: Formats other than TextFormat are written by a sink of the output,
+// sinks set for name or its nearer parent names take precedence.
+func (r *Registry) SetFormat(name string, format Formatter) {
+	name = strings.Trim(name, ".")
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.formats[name] = format
+	clear(r.formatSinks)
+	r.cascade(name)
+}
+
+// SetSinks sets sinks of name, it cascades to loggers of name.*
+// unless their sinks are set explicitly. An empty name sets sinks of
+// the root logger and all loggers.
+func (r *Registry) SetSinks(name string, sinks ...*Sink) {
+	name = strings.Trim(name, ".")
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.sinks[name] = sinks
+	r.cascade(name)
+}
+
+// get returns the logger of name, it must be called with lock held.
+func (r *Registry) get(name string) *Logger {
+	if l, ok := r.loggers[name]; ok {
+		return l
+	}
+
+	parent := r.root
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		parent = r.get(name[:i])
+	}
+
+	l := parent.New(name)
+	r.configure(name, l)
+
+	r.loggers[name] = l
+
+	return l
+}
+
+// cascade applies configs to loggers of name and name.*, an empty name
+// applies to the root logger and all loggers.
+func (r *Registry) cascade(name string) {
+	if name == "" {
+		r.configure("", r.root)
+	}
+
+	for key, l := range r.loggers {
+		if name == "" || key == name || strings.HasPrefix(key, name+".") {
+			r.configure(key, l)
+		}
+	}
+}
+
+// configure applies configs of the nearest name configured to the logger.
+func (r *Registry) configure(name string, l *Logger) {
+	if name != "" {
+		if lv, _, ok := resolveName(r.levels, name); ok {
+			l.inheritLevel(lv)
+		} else {
+			l.inheritLevel(r.root.LevelVar())
+		}
+	}
+
+	w, outputName, ok := resolveName(r.outputs, name)
+	if ok {
+		l.SetOutput(w)
+	} else if name != "" {
+		l.SetOutput(r.root.currentOutput())
+	}
+
+	sinks, sinksName, hasSinks := resolveName(r.sinks, name)
+	format, formatName, hasFormat := resolveName(r.formats, name)
+	switch {
+	case hasFormat && (!hasSinks || len(formatName) > len(sinksName)):
+		if format == TextFormat {
+			l.SetSinks()
+		} else {
+			key := formatKey{format: formatName, output: outputName}
+
+			sink, ok := r.formatSinks[key]
+			if !ok {
+				sink = &Sink{Name: "format", Writer: l.currentOutput(), Format: format}
+
+				r.formatSinks[key] = sink
+			}
+
+			l.SetSinks(sink)
+		}
+
+	case hasSinks:
+		l.SetSinks(sinks...)
+
+	case name != "":
+		l.inheritSinks()
+	}
+}
+
+// resolveName returns value of name or its nearest parent name in m, the empty
+// name is the parent of all names.
+func resolveName[T any](m map[string]T, name string) (T, string, bool) {
+	for {
+		if value, ok := m[name]; ok {
+			return value, name, true
+		}
+
+		if name == "" {
+			var zero T
+			return zero, "", false
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			i = 0
+		}
+
+		name = name[:i]
+	}
+}
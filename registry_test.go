@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Registry_Get(t *testing.T) {
+	assertion := assert.New(t)
+
+	root, _ := New("stdout")
+	registry := NewRegistry(root)
+
+	assertion.Equal(root, registry.Get(""))
+
+	pool := registry.Get("db.pool")
+	assertion.Equal(pool, registry.Get("db.pool"))
+	assertion.Equal(pool, registry.Get(".db.pool."))
+	assertion.Equal([]string{"db.pool"}, pool.Tags())
+	assertion.Equal([]string{"db"}, registry.Get("db").Tags())
+
+	names := registry.Names()
+	sort.Strings(names)
+	assertion.Equal([]string{"db", "db.pool"}, names)
+}
+
+func Test_Registry_SetLevel(t *testing.T) {
+	assertion := assert.New(t)
+
+	root, _ := New("stdout")
+	root.SetLevel(Lwarn)
+
+	registry := NewRegistry(root)
+
+	db := registry.Get("db")
+	pool := registry.Get("db.pool")
+	client := registry.Get("http.client")
+
+	// inherits level of root
+	assertion.Equal(Lwarn, db.Level())
+	assertion.Nil(registry.SetLevel("", Lerror))
+	assertion.Equal(Lerror, pool.Level())
+
+	// cascades to db.*
+	assertion.Nil(registry.SetLevel("db", Ldebug))
+	assertion.Equal(Ldebug, db.Level())
+	assertion.Equal(Ldebug, pool.Level())
+	assertion.Equal(Ldebug, registry.Get("db.pool.conn").Level())
+	assertion.Equal(Lerror, client.Level())
+	assertion.Equal(Lerror, root.Level())
+
+	// explicit level is not overridden by parent
+	assertion.Nil(registry.SetLevel("db.pool", Linfo))
+	assertion.Nil(registry.SetLevelByName("db", "warn"))
+	assertion.Equal(Lwarn, db.Level())
+	assertion.Equal(Linfo, pool.Level())
+	assertion.Equal(Linfo, registry.Get("db.pool.conn").Level())
+
+	assertion.Equal(ErrLevel, registry.SetLevel("db", lmax))
+	assertion.Equal(ErrLevel, registry.SetLevelByName("db", "unknown"))
+}
+
+func Test_Registry_SetOutput(t *testing.T) {
+	assertion := assert.New(t)
+
+	var rootBuf, dbBuf, jsonBuf bytes.Buffer
+
+	root, _ := New("stdout")
+	root.SetOutput(&rootBuf)
+
+	registry := NewRegistry(root)
+	pool := registry.Get("db.pool")
+
+	registry.SetOutput("db", &dbBuf)
+	pool.Info("pool")
+	registry.Get("db.conn").Info("conn")
+	registry.Get("http").Info("http")
+
+	assertion.Contains(dbBuf.String(), ", db.pool]")
+	assertion.Contains(dbBuf.String(), ", db.conn]")
+	assertion.NotContains(dbBuf.String(), "http")
+	assertion.Contains(rootBuf.String(), ", http]")
+
+	registry.SetSinks("db.pool", &Sink{Name: "json", Writer: &jsonBuf, Format: JSONFormat})
+	pool.Info("json")
+	assertion.Contains(jsonBuf.String(), `"tags":["db.pool"]`)
+	assertion.NotContains(dbBuf.String(), "json")
+}
+
+func Test_Registry_SetOutputOfRoot(t *testing.T) {
+	assertion := assert.New(t)
+
+	var rootBuf, dbBuf, sinkBuf bytes.Buffer
+
+	root, _ := New("stdout")
+	root.SetOutput(io.Discard)
+
+	registry := NewRegistry(root)
+	pool := registry.Get("db.pool")
+	http := registry.Get("http")
+
+	// loggers created already follow the root
+	registry.SetOutput("", &rootBuf)
+	root.Info("root")
+	pool.Info("pool")
+	http.Info("http")
+	assertion.Contains(rootBuf.String(), "root")
+	assertion.Contains(rootBuf.String(), ", db.pool]")
+	assertion.Contains(rootBuf.String(), ", http]")
+
+	// names configured take precedence over the root
+	registry.SetOutput("db", &dbBuf)
+	registry.SetOutput("", io.Discard)
+	pool.Info("pool")
+	assertion.Contains(dbBuf.String(), ", db.pool]")
+
+	registry.SetSinks("", &Sink{Name: "text", Writer: &sinkBuf})
+	http.Info("http sink")
+	pool.Info("pool sink")
+	assertion.Contains(sinkBuf.String(), "http sink")
+	assertion.Contains(sinkBuf.String(), "pool sink")
+
+	// sinks are restored by the root
+	sinkBuf.Reset()
+	registry.SetSinks("")
+	http.Info("http output")
+	assertion.Empty(sinkBuf.String())
+}
+
+func Test_Registry_SetFormat(t *testing.T) {
+	assertion := assert.New(t)
+
+	var rootBuf, dbBuf bytes.Buffer
+
+	root, _ := New("stdout")
+	root.SetOutput(&rootBuf)
+	root.SetColor(false)
+
+	registry := NewRegistry(root)
+	pool := registry.Get("db.pool")
+	http := registry.Get("http")
+
+	registry.SetFormat("", JSONFormat)
+	registry.SetOutput("db", &dbBuf)
+	pool.Info("pool")
+	http.Info("http")
+	assertion.Contains(dbBuf.String(), `"tags":["db.pool"]`)
+	assertion.Contains(rootBuf.String(), `"tags":["http"]`)
+
+	// text format of a name overrides json format of the root
+	dbBuf.Reset()
+	registry.SetFormat("db", TextFormat)
+	pool.Info("pool")
+	assertion.Contains(dbBuf.String(), "[INFO, db.pool]")
+
+	// sinks of nearer names take precedence over formats
+	var sinkBuf bytes.Buffer
+
+	registry.SetSinks("db.pool", &Sink{Name: "json", Writer: &sinkBuf, Format: JSONFormat})
+	pool.Info("pool")
+	assertion.Contains(sinkBuf.String(), `"tags":["db.pool"]`)
+}
+
+func Test_Registry_SetFormatWithSharedWriter(t *testing.T) {
+	assertion := assert.New(t)
+
+	var w lineWriter
+
+	root, _ := New("stdout")
+	root.SetOutput(&w)
+
+	registry := NewRegistry(root)
+	registry.SetFormat("", JSONFormat)
+
+	loggers := []*Logger{root, registry.Get("db"), registry.Get("http")}
+
+	// loggers with the same format and output share a sink
+	assertion.True(loggers[0].Sinks()[0] == loggers[1].Sinks()[0])
+	assertion.True(loggers[0].Sinks()[0] == loggers[2].Sinks()[0])
+
+	var wg sync.WaitGroup
+	for _, l := range loggers {
+		wg.Add(1)
+
+		go func(l *Logger) {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				l.Info("shared")
+			}
+		}(l)
+	}
+	wg.Wait()
+
+	assertion.False(w.overlap.Load())
+	assertion.Equal(300, len(w.lines))
+}
+
+func Test_Registry_Concurrent(t *testing.T) {
+	root, _ := New("stdout")
+	root.SetOutput(io.Discard)
+
+	registry := NewRegistry(root)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				registry.Get("db.pool").Debug("race")
+				registry.Get("db.pool.conn").Info("race")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				registry.SetLevel("db", Level(j%int(Lerror)+1))
+				registry.SetOutput("db.pool", io.Discard)
+				registry.SetFormat("", Formatter(j%2))
+			}
+		}()
+	}
+
+	wg.Wait()
+}
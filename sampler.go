@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSampleCounters caps keys tracked by sampler, logs of new keys
+	// beyond it are passed without sampling until counters are pruned.
+	maxSampleCounters = 4096
+
+	defaultSampleInterval = time.Second
+)
+
+// SetSampling samples logs of debug, info, warn and error levels keyed by level
+// and message template (format of Xxxf, or message). It passes the first logs
+// of each key within interval, and then every thereafter one. Counts of sampled
+// out logs are reported once per interval, default to 1 second. Counters idle for
+// an interval are pruned. A first of 0 disables it.
+// NOTE: The sampler is shared by all loggers created with l.New().
+func (l *Logger) SetSampling(first, thereafter int, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSampleInterval
+	}
+
+	var s *sampler
+	if first > 0 {
+		s = &sampler{
+			logger:     l,
+			first:      first,
+			thereafter: thereafter,
+			interval:   interval,
+			counters:   make(map[sampleKey]*sampleCounter),
+		}
+	}
+
+	l.tree.sampler.Store(s)
+}
+
+type sampleKey struct {
+	level    Level
+	template string
+}
+
+type sampleCounter struct {
+	since   time.Time
+	count   int
+	dropped int
+}
+
+type sampler struct {
+	mux sync.Mutex
+
+	logger     *Logger
+	first      int
+	thereafter int
+	interval   time.Duration
+	counters   map[sampleKey]*sampleCounter
+	reporting  bool // whether report is scheduled
+}
+
+// sample returns whether the log should be written.
+func (s *sampler) sample(level Level, template string) bool {
+	if s == nil {
+		return true
+	}
+
+	now := time.Now()
+	key := sampleKey{
+		level:    level,
+		template: template,
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	counter, ok := s.counters[key]
+	if !ok {
+		if len(s.counters) >= maxSampleCounters {
+			return true
+		}
+
+		counter = &sampleCounter{
+			since: now,
+		}
+
+		s.counters[key] = counter
+
+		// prunes counters no matter logs are dropped or not
+		s.schedule()
+	} else if now.Sub(counter.since) > s.interval {
+		counter.since = now
+		counter.count = 0
+	}
+	counter.count++
+
+	if counter.count <= s.first {
+		return true
+	}
+
+	if s.thereafter > 0 && (counter.count-s.first)%s.thereafter == 0 {
+		return true
+	}
+
+	counter.dropped++
+	s.schedule()
+
+	return false
+}
+
+// schedule schedules report after interval, it must be called with lock held.
+func (s *sampler) schedule() {
+	if s.reporting {
+		return
+	}
+
+	s.reporting = true
+
+	time.AfterFunc(s.interval, s.report)
+}
+
+// report logs counts of sampled out logs and prunes expired counters.
+func (s *sampler) report() {
+	now := time.Now()
+
+	type report struct {
+		key     sampleKey
+		dropped int
+	}
+
+	var reports []report
+
+	s.mux.Lock()
+	for key, counter := range s.counters {
+		if counter.dropped > 0 {
+			reports = append(reports, report{
+				key:     key,
+				dropped: counter.dropped,
+			})
+
+			counter.dropped = 0
+		}
+
+		if now.Sub(counter.since) > s.interval {
+			delete(s.counters, key)
+		}
+	}
+	s.reporting = false
+	if len(s.counters) > 0 {
+		s.schedule()
+	}
+	s.mux.Unlock()
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].key.level != reports[j].key.level {
+			return reports[i].key.level < reports[j].key.level
+		}
+
+		return reports[i].key.template < reports[j].key.template
+	})
+
+	for _, r := range reports {
+		s.logger.Output(r.key.level, fmt.Sprintf("sampled out %d logs of %q", r.dropped, r.key.template))
+	}
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_Sampling(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetSampling(2, 3, 50*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		logger.Debugf("hot loop #%d", i)
+	}
+	logger.Info("cold path")
+
+	output := buf.String()
+	assert.Equal(t, 4, strings.Count(output, "hot loop"))
+	assert.Contains(t, output, "hot loop #0")
+	assert.Contains(t, output, "hot loop #1")
+	assert.Contains(t, output, "hot loop #4")
+	assert.Contains(t, output, "hot loop #7")
+	assert.Contains(t, output, "cold path")
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Contains(t, buf.String(), `sampled out 6 logs of "hot loop #%d"`)
+
+	// new interval
+	logger.Debugf("hot loop #%d", 10)
+	assert.Contains(t, buf.String(), "hot loop #10")
+}
+
+func Test_Logger_SamplingWithStructLogger(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetSampling(1, 0, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		logger.New("child").NewJsonLogger().Str("key", "value").Infof("struct #%d", i)
+	}
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "struct #"))
+
+	// sampling is applied to struct loggers created before it
+	var before syncBuffer
+
+	other, _ := New("stdout")
+	other.SetOutput(&before)
+
+	log := other.NewTextLogger()
+	other.SetSampling(1, 0, time.Minute)
+	for i := 0; i < 3; i++ {
+		log.Infof("before #%d", i)
+	}
+
+	assert.Equal(t, 1, strings.Count(before.String(), "before #"))
+}
+
+func Test_Logger_SamplingPrunesCounters(t *testing.T) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+	logger.SetSampling(1, 0, 20*time.Millisecond)
+
+	s := logger.tree.sampler.Load()
+
+	// unique messages are never dropped
+	for i := 0; i < 100; i++ {
+		logger.Info("unique ", i)
+	}
+
+	s.mux.Lock()
+	assert.Equal(t, 100, len(s.counters))
+	s.mux.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+
+	s.mux.Lock()
+	assert.Equal(t, 0, len(s.counters))
+	s.mux.Unlock()
+
+	// new keys beyond the cap are passed without tracking, counters are not
+	// pruned while filling them
+	logger.SetSampling(1, 0, time.Hour)
+
+	s = logger.tree.sampler.Load()
+	for i := 0; i < maxSampleCounters+10; i++ {
+		assert.True(t, s.sample(Linfo, strconv.Itoa(i)))
+	}
+
+	s.mux.Lock()
+	assert.Equal(t, maxSampleCounters, len(s.counters))
+	s.mux.Unlock()
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// DefaultScopeSize is the max number of logs buffered by a scope by default.
+	DefaultScopeSize = 1024
+)
+
+type scopeKey struct{}
+
+// Scope is a Logger buffers all logs of a scope, e.g. an HTTP request,
+// including ones below the level of Logger. On Close, the whole sequence is
+// written if any log reaches the trigger level, otherwise logs below the
+// level of Logger are discarded.
+type Scope struct {
+	*Logger
+
+	buffer *scopeBuffer
+}
+
+// NewScope allocates a new Scope for given trigger level and tags.
+// NOTE: Loggers created with Scope.New() share the buffer of the scope.
+func (l *Logger) NewScope(trigger Level, tags ...string) *Scope {
+	buffer := &scopeBuffer{
+		trigger: trigger,
+		max:     DefaultScopeSize,
+	}
+
+	child := l.New(append(append([]string{}, l.Tags()...), tags...)...)
+	child.scope = buffer
+
+	return &Scope{
+		Logger: child,
+		buffer: buffer,
+	}
+}
+
+// SetMaxRecords caps logs buffered by the scope, a size of 0 means unlimited.
+// When the buffer is full, the oldest log is written if it's enabled by level
+// of Logger, otherwise it's discarded and counted.
+func (s *Scope) SetMaxRecords(size int) {
+	s.buffer.mux.Lock()
+	s.buffer.max = size
+	s.buffer.mux.Unlock()
+}
+
+// Triggered returns whether any log of the scope has reached the trigger level.
+func (s *Scope) Triggered() bool {
+	s.buffer.mux.Lock()
+	defer s.buffer.mux.Unlock()
+
+	return s.buffer.triggered
+}
+
+// Close writes buffered logs of the scope. Logs after Close are written directly.
+func (s *Scope) Close() error {
+	return s.buffer.flush(s.Logger)
+}
+
+// NewContext returns a new Context carries the Logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, scopeKey{}, l)
+}
+
+// FromContext returns the Logger stored in ctx, or nil if none.
+func FromContext(ctx context.Context) *Logger {
+	l, _ := ctx.Value(scopeKey{}).(*Logger)
+	return l
+}
+
+type scopeRecord struct {
+	Record
+
+	enabled bool
+}
+
+type scopeBuffer struct {
+	mux sync.Mutex
+
+	trigger   Level
+	triggered bool
+	closed    bool
+	max       int
+	dropped   int
+	records   []scopeRecord
+}
+
+// add buffers a record, it returns false if the scope has been closed, or the
+// record is fatal, panic or trace which is written synchronously for the process
+// may not survive it. The oldest record is evicted if the buffer is full,
+// it should be written if it's enabled.
+func (sb *scopeBuffer) add(r *Record, enabled bool) (evicted scopeRecord, ok bool) {
+	sb.mux.Lock()
+	defer sb.mux.Unlock()
+
+	if sb.closed {
+		return evicted, false
+	}
+
+	if r.Level >= sb.trigger && r.Level <= Ltrace {
+		sb.triggered = true
+	}
+
+	if r.Level >= Lfatal && r.Level <= Ltrace {
+		return evicted, false
+	}
+
+	if sb.max > 0 && len(sb.records) >= sb.max {
+		evicted = sb.records[0]
+
+		// slicing from front keeps memory bounded by reallocation of append
+		sb.records[0] = scopeRecord{}
+		sb.records = sb.records[1:]
+
+		if !evicted.enabled {
+			sb.dropped++
+		}
+	}
+
+	sb.records = append(sb.records, scopeRecord{
+		Record:  r.clone(),
+		enabled: enabled,
+	})
+
+	return evicted, true
+}
+
+// flush closes the buffer and writes records buffered with l, records below
+// level of Logger are written only if the scope is triggered.
+func (sb *scopeBuffer) flush(l *Logger) error {
+	sb.mux.Lock()
+	records, triggered, dropped := sb.records, sb.triggered, sb.dropped
+
+	sb.records = nil
+	sb.dropped = 0
+	sb.closed = true
+	sb.mux.Unlock()
+
+	if triggered && dropped > 0 {
+		r := l.newRecord(Llog, nil, fmt.Sprintf("--- scope: %d records dropped ---", dropped), l.Tags(), "", 0)
+		err := l.emit(r, true)
+
+		freeRecord(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, record := range records {
+		if !triggered && !record.enabled {
+			continue
+		}
+
+		if err := l.emit(&record.Record, triggered); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
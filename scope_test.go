@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_Scope(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetColor(false)
+	logger.SetLevel(Linfo)
+
+	scope := logger.NewScope(Lerror, "X-REQUEST-ID")
+	scope.Debug("debug detail")
+	scope.Info("request done")
+	assert.Empty(t, buf.String())
+	assert.False(t, scope.Triggered())
+
+	assert.Nil(t, scope.Close())
+	assert.NotContains(t, buf.String(), "debug detail")
+	assert.Contains(t, buf.String(), "[INFO, X-REQUEST-ID]")
+
+	// logs after close are written directly
+	buf.Reset()
+	scope.Info("after close")
+	assert.Contains(t, buf.String(), "after close")
+}
+
+func Test_Logger_ScopeWithTrigger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Linfo)
+
+	scope := logger.NewScope(Lerror)
+	scope.Debug("debug detail")
+	scope.New("db").NewTextLogger().Str("key", "value").Error("query failed")
+	assert.Empty(t, buf.String())
+	assert.True(t, scope.Triggered())
+
+	assert.Nil(t, scope.Close())
+	assert.Contains(t, buf.String(), "debug detail")
+	assert.Contains(t, buf.String(), "msg=query failed")
+}
+
+func Test_Logger_ScopeWithFatal(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Linfo)
+
+	scope := logger.NewScope(Lerror)
+	scope.Debug("debug detail")
+	scope.Info("request started")
+	assert.Empty(t, buf.String())
+
+	// fatal logs are written synchronously with buffered logs before them
+	assert.Nil(t, scope.Output(Lfatal, "fatal failure"))
+	assert.True(t, scope.Triggered())
+
+	output := buf.String()
+	assert.Contains(t, output, "debug detail")
+	assert.Contains(t, output, "fatal failure")
+	assert.True(t, strings.Index(output, "request started") < strings.Index(output, "fatal failure"))
+
+	buf.Reset()
+	assert.Nil(t, scope.Close())
+	assert.Empty(t, buf.String())
+}
+
+func Test_Logger_ScopeWithMaxRecords(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Linfo)
+
+	scope := logger.NewScope(Lerror)
+	scope.SetMaxRecords(2)
+
+	scope.Info("info 1")
+	scope.Debug("debug 2")
+	assert.Empty(t, buf.String())
+
+	// enabled logs evicted are written
+	scope.Debug("debug 3")
+	assert.Contains(t, buf.String(), "info 1")
+	assert.Equal(t, 2, len(scope.buffer.records))
+
+	// disabled logs evicted are dropped and counted
+	scope.Debug("debug 4")
+	scope.Error("failed")
+	assert.Nil(t, scope.Close())
+
+	output := buf.String()
+	assert.NotContains(t, output, "debug 2")
+	assert.NotContains(t, output, "debug 3")
+	assert.Contains(t, output, "--- scope: 2 records dropped ---")
+	assert.Contains(t, output, "debug 4")
+	assert.Contains(t, output, "failed")
+}
+
+func Test_Logger_Context(t *testing.T) {
+	logger, _ := New("nil")
+
+	assert.Nil(t, FromContext(context.Background()))
+
+	ctx := NewContext(context.Background(), logger)
+	assert.Equal(t, logger, FromContext(ctx))
+}
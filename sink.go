@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Sink is an output of Logger with its own level, format and color.
+type Sink struct {
+	// Name of sink, it's used for reporting errors.
+	Name string
+
+	// Writer of sink, errors of it are isolated from other sinks.
+	Writer io.Writer
+
+	// Level is the min level of logs written to the sink.
+	// NOTE: Logs below the level of Logger never reach sinks.
+	Level Level
+
+	// Format of sink, TextFormat writes the same as Logger does,
+	// JSONFormat writes a JSON object per line.
+	Format Formatter
+
+	// Color is whether text logs are written with colorful.
+	Color bool
+
+	mux sync.Mutex
+}
+
+// SetSinks fans out logs and writes of l.Write() to sinks instead of output of
+// Logger, errors of all sinks failed are joined. Calling it without sinks restores
+// the output. Writes of sinks are serialized with writes of the logger tree.
+// NOTE: Sinks are inherited by loggers created with l.New() unless they set their own.
+func (l *Logger) SetSinks(sinks ...*Sink) {
+	l.mux.Lock()
+	l.sinks = sinks
+	l.ownSinks = true
+	l.mux.Unlock()
+}
+
+// inheritSinks drops sinks of the logger for inheriting ones of its parent.
+func (l *Logger) inheritSinks() {
+	l.mux.Lock()
+	l.sinks = nil
+	l.ownSinks = false
+	l.mux.Unlock()
+}
+
+// Sinks returns sinks of the logger, or ones inherited from its parent.
+func (l *Logger) Sinks() []*Sink {
+	for p := l; p != nil; p = p.parent {
+		p.mux.RLock()
+		sinks, ok := p.sinks, p.ownSinks
+		p.mux.RUnlock()
+
+		if ok {
+			return sinks
+		}
+	}
+
+	return nil
+}
+
+// formatJSON formats the record as a JSON object in one line.
+func (l *Logger) formatJSON(buf *bytes.Buffer, r *Record) {
+	buf.WriteString(`{"time":"`)
+	buf.Write(r.Time.AppendFormat(buf.AvailableBuffer(), time.RFC3339Nano))
+	buf.WriteByte('"')
+
+	buf.WriteString(`,"level":`)
+	writeJSONString(buf, r.Level.String())
+
+	if len(r.Tags) > 0 {
+		buf.WriteString(`,"tags":[`)
+		for i, tag := range r.Tags {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeJSONString(buf, tag)
+		}
+		buf.WriteByte(']')
+	}
+
+	if l.flag&(log.Lshortfile|log.Llongfile) != 0 {
+		buf.WriteString(`,"caller":`)
+		writeJSONString(buf, l.shortFile(r.File))
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(r.Line), 10))
+		buf.WriteByte('"')
+	}
+
+	buf.WriteString(`,"msg":`)
+	if l.escape == EscapeNone {
+		writeJSONString(buf, r.Message)
+	} else {
+		writeJSONString(buf, stripANSI(r.Message))
+	}
+
+	if len(r.Fields) > 0 {
+		buf.WriteString(`,"fields":`)
+
+		enc := jsonEncoder{buf: buf}
+		enc.encodeAttrs(r.Fields, 0)
+	}
+
+	if len(r.stacks) > 0 {
+		buf.WriteString(`,"stack":`)
+		writeJSONString(buf, string(r.stacks))
+	}
+
+	buf.WriteString("}\n")
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+type failWriter struct{}
+
+func (failWriter) Write(b []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func Test_Logger_SetSinks(t *testing.T) {
+	var (
+		stderr, file, errorFile bytes.Buffer
+	)
+
+	logger, _ := New("stdout")
+	logger.SetTags("sink")
+	logger.SetSinks(
+		&Sink{Name: "stderr", Writer: &stderr, Level: Ldebug, Color: true},
+		&Sink{Name: "file", Writer: &file, Level: Linfo, Format: JSONFormat},
+		&Sink{Name: "error", Writer: &errorFile, Level: Lerror},
+	)
+
+	logger.Debug("debugging")
+	logger.NewTextLogger().Str("key", "value").Info("informing")
+	logger.Error("failed")
+
+	assert.Equal(t, 3, strings.Count(stderr.String(), "\n"))
+	assert.Contains(t, stderr.String(), "\x1b[")
+	assert.Contains(t, stderr.String(), "key=value, msg=informing")
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	var record map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "informing", record["msg"])
+	assert.Equal(t, []any{"sink"}, record["tags"])
+	assert.Equal(t, map[string]any{"key": "value"}, record["fields"])
+	assert.NotEmpty(t, record["caller"])
+
+	assert.NotContains(t, errorFile.String(), "informing")
+	assert.Contains(t, errorFile.String(), "[ERROR, sink]")
+	assert.NotContains(t, errorFile.String(), "\x1b[")
+}
+
+func Test_Logger_SetSinksWithError(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetSinks(
+		&Sink{Name: "broken", Writer: failWriter{}},
+		&Sink{Name: "buffer", Writer: &buf},
+	)
+
+	err := logger.Output(Linfo, "hello")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "sink broken: disk full")
+	assert.Contains(t, buf.String(), "hello")
+}
+
+func Test_Logger_SetSinksWithSharedWriter(t *testing.T) {
+	var w lineWriter
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&w)
+	logger.SetColor(false)
+
+	// the sink of child writes to the output of logger
+	child := logger.New("child")
+	child.SetSinks(&Sink{Name: "json", Writer: &w, Format: JSONFormat})
+
+	var wg sync.WaitGroup
+	for _, l := range []*Logger{logger, child} {
+		wg.Add(1)
+
+		go func(l *Logger) {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				l.Info("shared")
+			}
+		}(l)
+	}
+	wg.Wait()
+
+	assert.False(t, w.overlap.Load())
+	assert.Equal(t, 200, len(w.lines))
+}
+
+func Test_Logger_WriteWithSinks(t *testing.T) {
+	var buf, sink bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetSinks(&Sink{Name: "sink", Writer: &sink})
+
+	n, err := logger.Write([]byte("raw\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "raw\n", sink.String())
+	assert.Empty(t, buf.String())
+}
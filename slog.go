@@ -29,7 +29,7 @@ func (l *Logger) Log(ctx context.Context, slogLevel slog.Level, msg string, args
 	}
 }
 
-// LogAttrs drops in of slog.LogAttrs
+// LogAttrs drops in of slog.LogAttrs, it doesn't allocate for common types of attrs.
 func (l *Logger) LogAttrs(ctx context.Context, slogLevel slog.Level, msg string, slogAttrs ...slog.Attr) {
 	var level = Ltrace
 	switch slogLevel {
@@ -43,10 +43,18 @@ func (l *Logger) LogAttrs(ctx context.Context, slogLevel slog.Level, msg string,
 		level = Lerror
 	}
 
-	log := l.NewTextLogger().(*structLog)
-	_ = log.writer(level, &attrs{
-		format: log.format,
-		stacks: log.stacks,
+	if !l.accept(level) {
+		return
+	}
+
+	as := &attrs{
+		format: TextFormat,
 		fields: slogAttrs,
-	}, msg)
+	}
+	if l.minLevel() > level {
+		l.record(level, as, msg)
+		return
+	}
+
+	_ = l.output(level, as, msg)
 }
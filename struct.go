@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"log/slog"
 	"runtime/debug"
 	"time"
 )
@@ -11,12 +12,20 @@ type (
 	StructLogger interface {
 		Str(key, value string) StructLogger
 		Bool(key string, value bool) StructLogger
+		Int(key string, value int) StructLogger
+		Float64(key string, value float64) StructLogger
 		Duration(key string, value time.Duration) StructLogger
 		Time(key string, value time.Time) StructLogger
 		Err(err error, stack bool) StructLogger
 		Any(key string, value any) StructLogger
 		Fields(fields map[string]any) StructLogger
 
+		Once(key ...string) StructLogger
+		FirstN(n int, key ...string) StructLogger
+		EveryN(n int, key ...string) StructLogger
+		Every(d time.Duration, key ...string) StructLogger
+		V(n int) StructLogger
+
 		Debug(msg string)
 		Debugf(format string, args ...any)
 		Info(msg string)
@@ -34,37 +43,61 @@ type (
 
 var (
 	_ StructLogger = (*structLog)(nil)
+	_ StructLogger = nopLog{}
 )
 
 type structLog struct {
-	writer func(level Level, as *attrs, msg string) error
+	logger *Logger
 	format Formatter
-	attrs  []Attr
+	fields []slog.Attr
 	stacks []byte
+
+	// inline storage of fields for avoiding allocations
+	inline [4]slog.Attr
+}
+
+func (l *Logger) newStructLog(format Formatter, attrs []Attr) *structLog {
+	log := &structLog{
+		logger: l,
+		format: format,
+	}
+	log.fields = appendAttrs(log.inline[:0], attrs...)
+
+	return log
 }
 
 func (log *structLog) Str(key, value string) StructLogger {
-	log.attrs = append(log.attrs, String(key, value))
+	log.fields = append(log.fields, slog.String(key, value))
 	return log
 }
 
 func (log *structLog) Bool(key string, value bool) StructLogger {
-	log.attrs = append(log.attrs, Bool(key, value))
+	log.fields = append(log.fields, slog.Bool(key, value))
+	return log
+}
+
+func (log *structLog) Int(key string, value int) StructLogger {
+	log.fields = append(log.fields, slog.Int(key, value))
+	return log
+}
+
+func (log *structLog) Float64(key string, value float64) StructLogger {
+	log.fields = append(log.fields, slog.Float64(key, value))
 	return log
 }
 
 func (log *structLog) Duration(key string, value time.Duration) StructLogger {
-	log.attrs = append(log.attrs, Duration(key, value))
+	log.fields = append(log.fields, slog.Duration(key, value))
 	return log
 }
 
 func (log *structLog) Time(key string, value time.Time) StructLogger {
-	log.attrs = append(log.attrs, Time(key, value))
+	log.fields = append(log.fields, slog.Time(key, value))
 	return log
 }
 
 func (log *structLog) Any(key string, value any) StructLogger {
-	log.attrs = append(log.attrs, Any(key, value))
+	log.fields = append(log.fields, slog.Any(key, value))
 	return log
 }
 
@@ -73,7 +106,7 @@ func (log *structLog) Err(err error, stack bool) StructLogger {
 		return log
 	}
 
-	log.attrs = append(log.attrs, Err(err))
+	log.fields = append(log.fields, slog.Attr(Err(err)))
 	if stack {
 		log.stacks = debug.Stack()
 	}
@@ -103,62 +136,134 @@ func (log *structLog) Fields(fields map[string]any) StructLogger {
 	return log
 }
 
-func (log *structLog) fields() *attrs {
-	as := &attrs{
+func (log *structLog) attrs() *attrs {
+	return &attrs{
 		format: log.format,
+		fields: log.fields,
 		stacks: log.stacks,
 	}
-	for _, attr := range log.attrs {
-		attr(as)
-	}
-
-	return as
 }
 
 func (log *structLog) Debug(msg string) {
-	_ = log.writer(Ldebug, log.fields(), msg)
+	if !log.logger.accept(Ldebug) || !log.logger.tree.sampler.Load().sample(Ldebug, msg) {
+		return
+	}
+
+	if log.logger.minLevel() > Ldebug {
+		log.logger.record(Ldebug, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Ldebug, log.attrs(), msg)
 }
 
 func (log *structLog) Debugf(format string, args ...any) {
-	_ = log.writer(Ldebug, log.fields(), fmt.Sprintf(format, args...))
+	if !log.logger.accept(Ldebug) || !log.logger.tree.sampler.Load().sample(Ldebug, format) {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if log.logger.minLevel() > Ldebug {
+		log.logger.record(Ldebug, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Ldebug, log.attrs(), msg)
 }
 
 func (log *structLog) Info(msg string) {
-	_ = log.writer(Linfo, log.fields(), msg)
+	if !log.logger.accept(Linfo) || !log.logger.tree.sampler.Load().sample(Linfo, msg) {
+		return
+	}
+
+	if log.logger.minLevel() > Linfo {
+		log.logger.record(Linfo, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Linfo, log.attrs(), msg)
 }
 
 func (log *structLog) Infof(format string, args ...any) {
-	_ = log.writer(Linfo, log.fields(), fmt.Sprintf(format, args...))
+	if !log.logger.accept(Linfo) || !log.logger.tree.sampler.Load().sample(Linfo, format) {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if log.logger.minLevel() > Linfo {
+		log.logger.record(Linfo, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Linfo, log.attrs(), msg)
 }
 
 func (log *structLog) Warn(msg string) {
-	_ = log.writer(Lwarn, log.fields(), msg)
+	if !log.logger.accept(Lwarn) || !log.logger.tree.sampler.Load().sample(Lwarn, msg) {
+		return
+	}
+
+	if log.logger.minLevel() > Lwarn {
+		log.logger.record(Lwarn, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Lwarn, log.attrs(), msg)
 }
 
 func (log *structLog) Warnf(format string, args ...any) {
-	_ = log.writer(Lwarn, log.fields(), fmt.Sprintf(format, args...))
+	if !log.logger.accept(Lwarn) || !log.logger.tree.sampler.Load().sample(Lwarn, format) {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if log.logger.minLevel() > Lwarn {
+		log.logger.record(Lwarn, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Lwarn, log.attrs(), msg)
 }
 
 func (log *structLog) Error(msg string) {
-	_ = log.writer(Lerror, log.fields(), msg)
+	if !log.logger.accept(Lerror) || !log.logger.tree.sampler.Load().sample(Lerror, msg) {
+		return
+	}
+
+	if log.logger.minLevel() > Lerror {
+		log.logger.record(Lerror, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Lerror, log.attrs(), msg)
 }
 
 func (log *structLog) Errorf(format string, args ...any) {
-	_ = log.writer(Lerror, log.fields(), fmt.Sprintf(format, args...))
+	if !log.logger.accept(Lerror) || !log.logger.tree.sampler.Load().sample(Lerror, format) {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if log.logger.minLevel() > Lerror {
+		log.logger.record(Lerror, log.attrs(), msg)
+		return
+	}
+
+	_ = log.logger.output(Lerror, log.attrs(), msg)
 }
 
 func (log *structLog) Fatal(msg string) {
-	_ = log.writer(Lfatal, log.fields(), msg)
+	_ = log.logger.output(Lfatal, log.attrs(), msg)
 }
 
 func (log *structLog) Fatalf(format string, args ...any) {
-	_ = log.writer(Lfatal, log.fields(), fmt.Sprintf(format, args...))
+	_ = log.logger.output(Lfatal, log.attrs(), fmt.Sprintf(format, args...))
 }
 
 func (log *structLog) Panic(msg string) {
-	_ = log.writer(Lpanic, log.fields(), msg)
+	_ = log.logger.output(Lpanic, log.attrs(), msg)
 }
 
 func (log *structLog) Panicf(format string, args ...any) {
-	_ = log.writer(Lpanic, log.fields(), fmt.Sprintf(format, args...))
+	_ = log.logger.output(Lpanic, log.attrs(), fmt.Sprintf(format, args...))
 }
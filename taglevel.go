@@ -0,0 +1,84 @@
+package logger
+
+// SetTagLevel overrides min level of output for logs tagged with tag, e.g.
+// debug for logs tagged payments while others stay at info. The lowest level
+// wins if more than one tag of a logger is overridden.
+// NOTE: Levels of tags are shared by all loggers created with l.New().
+func (l *Logger) SetTagLevel(tag string, level Level) error {
+	if !level.IsValid() {
+		return ErrLevel
+	}
+
+	l.rules.mux.Lock()
+	defer l.rules.mux.Unlock()
+
+	levels := map[string]Level{}
+	if prev := l.rules.levels.Load(); prev != nil {
+		for key, value := range *prev {
+			levels[key] = value
+		}
+	}
+	levels[tag] = level
+
+	l.rules.levels.Store(&levels)
+
+	return nil
+}
+
+// SetTagLevelByName overrides min level of output for logs tagged with tag by level name.
+// It returns ErrLevel for invalid name.
+func (l *Logger) SetTagLevelByName(tag, name string) error {
+	return l.SetTagLevel(tag, ResolveLevelByName(name))
+}
+
+// RemoveTagLevel removes level override of tag.
+func (l *Logger) RemoveTagLevel(tag string) {
+	l.rules.mux.Lock()
+	defer l.rules.mux.Unlock()
+
+	prev := l.rules.levels.Load()
+	if prev == nil {
+		return
+	}
+	if _, ok := (*prev)[tag]; !ok {
+		return
+	}
+
+	levels := map[string]Level{}
+	for key, value := range *prev {
+		if key != tag {
+			levels[key] = value
+		}
+	}
+
+	if len(levels) == 0 {
+		l.rules.levels.Store(nil)
+	} else {
+		l.rules.levels.Store(&levels)
+	}
+}
+
+// tagLevel returns the lowest level overridden of tags.
+func (rs *rules) tagLevel(tags []string) (Level, bool) {
+	if rs == nil || len(tags) == 0 {
+		return lmin, false
+	}
+
+	levels := rs.levels.Load()
+	if levels == nil {
+		return lmin, false
+	}
+
+	var (
+		level Level
+		found bool
+	)
+	for _, tag := range tags {
+		if value, ok := (*levels)[tag]; ok && (!found || value < level) {
+			level = value
+			found = true
+		}
+	}
+
+	return level, found
+}
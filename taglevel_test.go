@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_SetTagLevel(t *testing.T) {
+	assertion := assert.New(t)
+
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Linfo)
+
+	payments := logger.New("payments")
+	orders := logger.New("orders")
+
+	assertion.Nil(logger.SetTagLevel("payments", Ldebug))
+	assertion.True(payments.Enabled(Ldebug))
+	assertion.False(orders.Enabled(Ldebug))
+	assertion.False(logger.Enabled(Ldebug))
+
+	payments.Debug("verbose payments")
+	orders.Debug("verbose orders")
+	payments.NewTextLogger().Str("id", "p1").Debug("struct payments")
+	assertion.Contains(buf.String(), "verbose payments")
+	assertion.Contains(buf.String(), "id=p1, msg=struct payments")
+	assertion.NotContains(buf.String(), "verbose orders")
+
+	// tags of SetTags and AddTags
+	orders.AddTags("payments")
+	assertion.True(orders.Enabled(Ldebug))
+
+	orders.SetTags("orders")
+	assertion.False(orders.Enabled(Ldebug))
+
+	// levels of tags can be raised, the lowest one wins
+	assertion.Nil(logger.SetTagLevelByName("orders", "error"))
+	assertion.False(orders.Enabled(Lwarn))
+
+	orders.AddTags("payments")
+	assertion.True(orders.Enabled(Ldebug))
+
+	// remove
+	logger.RemoveTagLevel("payments")
+	assertion.False(payments.Enabled(Ldebug))
+	assertion.True(payments.Enabled(Linfo))
+	logger.RemoveTagLevel("unknown")
+
+	assertion.Equal(ErrLevel, logger.SetTagLevel("payments", lmax))
+	assertion.Equal(ErrLevel, logger.SetTagLevelByName("payments", "unknown"))
+}
+
+func Test_Logger_SetTagLevelConcurrent(t *testing.T) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+
+	payments := logger.New("payments")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				logger.SetTagLevel("payments", Level(j%int(Lerror)+1))
+				logger.RemoveTagLevel("payments")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				payments.Debug("race")
+				payments.AddTags("orders")
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// tree holds features shared by a logger tree, i.e. a logger created by New()
+// and all loggers derived from it with l.New(), no matter they're derived before
+// or after the feature is set. Features are stored behind atomics for reading
+// without locks on the hot path.
+type tree struct {
+	escalation atomic.Pointer[escalation]
+	sampler    atomic.Pointer[sampler]
+	dedup      atomic.Pointer[dedup]
+	limiter    atomic.Pointer[limiter]
+	limits     atomic.Pointer[Limits]
+	recorder   atomic.Pointer[flightRecorder]
+	crash      atomic.Pointer[CrashReport]
+
+	// states of gates keyed by call site or explicit key
+	gates sync.Map
+}
+
+func newTree() *tree {
+	return &tree{}
+}
@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_TreeSharedAfterNew(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Lwarn)
+
+	// children are derived before features are set
+	child := logger.New("child")
+	grandchild := child.New("grandchild")
+
+	assert.Nil(t, logger.SetEscalation(2, time.Second, time.Minute, Ldebug))
+	defer logger.SetEscalation(0, 0, 0, Ldebug)
+
+	child.Error("error 1")
+	grandchild.Error("error 2")
+	assert.True(t, logger.Escalated())
+	assert.True(t, grandchild.Enabled(Ldebug))
+}
+
+func Test_Logger_TreeRecorderAfterNew(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLevel(Lwarn)
+
+	child := logger.New("child")
+
+	logger.SetFlightRecorder(10)
+	defer logger.SetFlightRecorder(0)
+
+	child.Debug("kept by recorder")
+	assert.NotContains(t, buf.String(), "kept by recorder")
+
+	child.Error("failed")
+	assert.Contains(t, buf.String(), "kept by recorder")
+}
+
+func Test_Logger_TreeSamplingAfterNew(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	child := logger.New("child")
+
+	// set by a child applies to the tree
+	child.New("grandchild").SetSampling(1, 0, time.Minute)
+	defer logger.SetSampling(0, 0, 0)
+
+	for i := 0; i < 3; i++ {
+		child.Warn("sampled")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "sampled"))
+}
+
+func Test_Logger_TreeDedupAfterNew(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	child := logger.New("child")
+	grandchild := child.New("grandchild")
+
+	logger.SetDedup(time.Minute)
+	for i := 0; i < 3; i++ {
+		child.Warn("repeated")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "repeated\n"))
+
+	// set by a child applies to the tree
+	grandchild.SetDedup(0)
+	for i := 0; i < 2; i++ {
+		logger.Warn("again")
+	}
+	assert.Equal(t, 2, strings.Count(buf.String(), "again"))
+}
+
+func Test_Logger_TreeRateLimitAfterNew(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	child := logger.New("child")
+
+	logger.SetRateLimit(&RateLimit{
+		By:     RateByTag,
+		Rate:   0.001,
+		Burst:  1,
+		Report: time.Minute,
+	})
+	defer logger.SetRateLimit(nil)
+
+	for i := 0; i < 3; i++ {
+		child.Warn("limited")
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), "limited"))
+}
+
+func Test_Logger_TreeHooksAfterNew(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	child := logger.New("child")
+
+	var fired []string
+	logger.AddHook(HookFunc(func(r *Record) bool {
+		fired = append(fired, "parent")
+		return true
+	}))
+	child.AddHook(HookFunc(func(r *Record) bool {
+		fired = append(fired, "child")
+		return true
+	}))
+
+	child.Info("hooked")
+	assert.Equal(t, []string{"parent", "child"}, fired)
+
+	logger.Info("parent only")
+	assert.Equal(t, []string{"parent", "child", "parent"}, fired)
+}
+
+func Test_Logger_TreeSinksAfterNew(t *testing.T) {
+	var buf, sink bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	child := logger.New("child")
+
+	logger.SetSinks(&Sink{Name: "sink", Writer: &sink})
+	child.Info("to sink")
+	assert.Contains(t, sink.String(), "to sink")
+	assert.NotContains(t, buf.String(), "to sink")
+
+	// own sinks override inherited ones
+	child.SetSinks()
+	child.Info("to output")
+	assert.Contains(t, buf.String(), "to output")
+	assert.NotContains(t, sink.String(), "to output")
+}
+
+func Test_Logger_TreeLimitsAfterNew(t *testing.T) {
+	var buf syncBuffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	grandchild := logger.New("child").New("grandchild")
+
+	logger.SetLimits(&Limits{MaxMessage: 4})
+	grandchild.Warn("truncated")
+	assert.Contains(t, buf.String(), "trun...(truncated, 9 bytes)")
+}
+
+func Test_Logger_TreeCrashReportAfterNew(t *testing.T) {
+	dir := t.TempDir()
+
+	logger, _ := New(filepath.Join(dir, "app.log"))
+	child := logger.New("child")
+
+	logger.SetCrashReport(&CrashReport{})
+	assert.Panics(t, func() {
+		defer Recover(child, Repanic())
+
+		panic("boom")
+	})
+
+	files, _ := filepath.Glob(filepath.Join(dir, "app.log.crash.*"))
+	assert.Equal(t, 1, len(files))
+
+	// disabled by children as well
+	child.SetCrashReport(nil)
+	assert.Panics(t, func() {
+		defer Recover(logger, Repanic())
+
+		panic("boom")
+	})
+
+	files, _ = filepath.Glob(filepath.Join(dir, "app.log.crash.*"))
+	assert.Equal(t, 1, len(files))
+}
@@ -0,0 +1,256 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// TruncatedKey is the key of map entry recording elements truncated.
+	TruncatedKey = "..."
+
+	// maxTruncateDepth guards against cyclic values if MaxDepth is not set.
+	maxTruncateDepth = 32
+)
+
+// Limits defines caps of records, values of zero mean unlimited. Truncated values
+// are marked with the original size, e.g. "...(truncated, 1048576 bytes)".
+type Limits struct {
+	// MaxMessage is the max length of message in bytes.
+	MaxMessage int
+
+	// MaxString is the max length of string fields in bytes, including strings
+	// nested in Any values.
+	MaxString int
+
+	// MaxElements is the max number of elements of slices, arrays and maps of Any values.
+	MaxElements int
+
+	// MaxDepth is the max nesting depth of groups and Any values.
+	MaxDepth int
+}
+
+// SetLimits truncates messages and fields exceeding limits, a nil value disables it.
+// NOTE: Limits are shared by all loggers created with l.New().
+func (l *Logger) SetLimits(limits *Limits) {
+	var lim *Limits
+	if limits != nil {
+		copied := *limits
+		lim = &copied
+	}
+
+	l.tree.limits.Store(lim)
+}
+
+// truncate truncates message and fields of the record exceeding limits.
+func (lim *Limits) truncate(r *Record) {
+	if lim == nil {
+		return
+	}
+
+	r.Message = lim.truncateString(r.Message, lim.MaxMessage)
+
+	if fields, ok := lim.truncateAttrs(r.Fields, 0); ok {
+		r.Fields = fields
+	}
+}
+
+// truncateAttrs returns a copy of attrs truncated if it's changed.
+func (lim *Limits) truncateAttrs(attrs []slog.Attr, depth int) ([]slog.Attr, bool) {
+	var truncated []slog.Attr
+	for i, attr := range attrs {
+		if value, ok := lim.truncateAttr(attr, depth); ok {
+			if truncated == nil {
+				truncated = append([]slog.Attr(nil), attrs...)
+			}
+
+			truncated[i] = value
+		}
+	}
+
+	if truncated == nil {
+		return attrs, false
+	}
+
+	return truncated, true
+}
+
+func (lim *Limits) truncateAttr(attr slog.Attr, depth int) (slog.Attr, bool) {
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		s := attr.Value.String()
+		if value := lim.truncateString(s, lim.MaxString); len(value) != len(s) {
+			return slog.String(attr.Key, value), true
+		}
+
+	case slog.KindGroup:
+		if lim.exceeded(depth + 1) {
+			return slog.String(attr.Key, truncatedDepth(attr.Value.Group())), true
+		}
+
+		if group, ok := lim.truncateAttrs(attr.Value.Group(), depth+1); ok {
+			return slog.Attr{Key: attr.Key, Value: slog.GroupValue(group...)}, true
+		}
+
+	case slog.KindAny:
+		if value, ok := lim.truncateValue(attr.Value.Any(), depth); ok {
+			return slog.Any(attr.Key, value), true
+		}
+	}
+
+	return attr, false
+}
+
+// truncateValue returns a copy of value truncated if it's changed.
+func (lim *Limits) truncateValue(value any, depth int) (any, bool) {
+	if value == nil {
+		return value, false
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return value, false
+		}
+
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		s := rv.String()
+		if truncated := lim.truncateString(s, lim.MaxString); len(truncated) != len(s) {
+			return truncated, true
+		}
+
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return value, false
+		}
+		if lim.exceeded(depth + 1) {
+			return truncatedDepth(value), true
+		}
+
+		n := rv.Len()
+		if lim.MaxElements > 0 && n > lim.MaxElements {
+			n = lim.MaxElements
+		}
+
+		changed := n < rv.Len()
+		truncated := make([]any, n, n+1)
+		for i := 0; i < n; i++ {
+			v, ok := lim.truncateValue(rv.Index(i).Interface(), depth+1)
+			truncated[i] = v
+			changed = changed || ok
+		}
+
+		if !changed {
+			return value, false
+		}
+		if n < rv.Len() {
+			truncated = append(truncated, truncatedElements(rv.Len()))
+		}
+
+		return truncated, true
+
+	case reflect.Map:
+		if lim.exceeded(depth + 1) {
+			return truncatedDepth(value), true
+		}
+
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		n := len(keys)
+		if lim.MaxElements > 0 && n > lim.MaxElements {
+			n = lim.MaxElements
+		}
+
+		changed := n < len(keys)
+		truncated := make(map[string]any, n+1)
+		for _, key := range keys[:n] {
+			v, ok := lim.truncateValue(rv.MapIndex(key).Interface(), depth+1)
+			truncated[fmt.Sprint(key.Interface())] = v
+			changed = changed || ok
+		}
+
+		if !changed {
+			return value, false
+		}
+		if n < len(keys) {
+			truncated[TruncatedKey] = truncatedElements(len(keys))
+		}
+
+		return truncated, true
+
+	case reflect.Struct:
+		if lim.exceeded(depth + 1) {
+			return truncatedDepth(value), true
+		}
+
+		changed := false
+		truncated := make(map[string]any, rv.NumField())
+
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name := field.Name
+			if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+
+			v, ok := lim.truncateValue(rv.Field(i).Interface(), depth+1)
+			truncated[name] = v
+			changed = changed || ok
+		}
+
+		if changed {
+			return truncated, true
+		}
+	}
+
+	return value, false
+}
+
+// truncateString truncates s to at most max bytes without breaking runes.
+func (lim *Limits) truncateString(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return fmt.Sprintf("%s...(truncated, %d bytes)", s[:n], len(s))
+}
+
+// exceeded returns whether depth exceeds MaxDepth.
+func (lim *Limits) exceeded(depth int) bool {
+	if lim.MaxDepth > 0 {
+		return depth > lim.MaxDepth
+	}
+
+	return depth > maxTruncateDepth
+}
+
+func truncatedElements(n int) string {
+	return fmt.Sprintf("...(truncated, %d elements)", n)
+}
+
+func truncatedDepth(value any) string {
+	return fmt.Sprintf("...(truncated, %T)", value)
+}
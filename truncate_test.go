@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_SetLimits(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLimits(&Limits{MaxMessage: 16, MaxString: 8})
+
+	logger.Infof("%s", strings.Repeat("x", 1024))
+	assert.Contains(t, buf.String(), strings.Repeat("x", 16)+"...(truncated, 1024 bytes)\n")
+	assert.NotContains(t, buf.String(), strings.Repeat("x", 17))
+
+	buf.Reset()
+	logger.NewTextLogger().Str("key", "0123456789").Str("short", "value").Info("text")
+	assert.Contains(t, buf.String(), `key="01234567...(truncated, 10 bytes)", short=value, msg=text`)
+
+	// children share limits
+	buf.Reset()
+	logger.New("child").Info(strings.Repeat("y", 32))
+	assert.Contains(t, buf.String(), "...(truncated, 32 bytes)")
+
+	// disable
+	buf.Reset()
+	logger.SetLimits(nil)
+	logger.Info(strings.Repeat("z", 32))
+	assert.NotContains(t, buf.String(), "truncated")
+}
+
+func Test_Logger_SetLimitsRune(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLimits(&Limits{MaxMessage: 4})
+
+	logger.Info("日本語")
+	assert.Contains(t, buf.String(), "日...(truncated, 9 bytes)")
+}
+
+func Test_Logger_SetLimitsCollection(t *testing.T) {
+	type item struct {
+		Name   string `json:"name"`
+		Hidden string `json:"-"`
+		Nested any    `json:"nested"`
+	}
+
+	lim := &Limits{MaxString: 4, MaxElements: 2, MaxDepth: 2}
+
+	value, ok := lim.truncateValue([]int{1, 2, 3, 4, 5}, 0)
+	assert.True(t, ok)
+	assert.Equal(t, []any{1, 2, "...(truncated, 5 elements)"}, value)
+
+	value, ok = lim.truncateValue(map[string]int{"a": 1, "b": 2, "c": 3}, 0)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{"a": 1, "b": 2, "...": "...(truncated, 3 elements)"}, value)
+
+	value, ok = lim.truncateValue(item{Name: "abcdefgh", Hidden: "secret", Nested: map[string]any{"deep": []int{1}}}, 0)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]any{
+		"name":   "abcd...(truncated, 8 bytes)",
+		"nested": map[string]any{"deep": "...(truncated, []int)"},
+	}, value)
+
+	small := []string{"ok"}
+	value, ok = lim.truncateValue(small, 0)
+	assert.False(t, ok)
+	assert.Equal(t, small, value)
+
+	// text format
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetLimits(lim)
+
+	logger.NewTextLogger().Any("list", []int{1, 2, 3}).Info("collections")
+	assert.Contains(t, buf.String(), `list="[1 2 ...(truncated, 3 elements)]", msg=collections`)
+}
+
+func Test_Logger_SetLimitsCycle(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	n := &node{Name: "cycle"}
+	n.Next = n
+
+	lim := &Limits{MaxString: 2}
+
+	value, ok := lim.truncateValue(n, 0)
+	assert.True(t, ok)
+	assert.Contains(t, fmt.Sprint(value), "...(truncated, *logger.node)")
+}
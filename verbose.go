@@ -0,0 +1,380 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// verbosity holds glog style verbosity shared by a logger tree.
+type verbosity struct {
+	mux sync.Mutex
+
+	// max is the highest level of level and vmodule, V(n) above it is disabled
+	// with a single atomic load.
+	max     atomic.Int32
+	level   atomic.Int32
+	modules atomic.Pointer[vmodule]
+}
+
+// vmodule is a compiled vmodule setting, decisions of call sites are cached
+// until the setting changes.
+type vmodule struct {
+	spec     string
+	patterns []modulePattern
+	sites    sync.Map // pc -> int32
+}
+
+type modulePattern struct {
+	pattern string
+	slashes int
+	level   int32
+}
+
+// V returns a Verbose logs with l if verbosity of the call site is at least n,
+// and discards all logs for others, e.g. l.V(3).Infof("cache miss of %s", key)
+// Verbosity of a call site is the level of the first vmodule pattern matching
+// its file, or the level set by SetVerbosity.
+func (l *Logger) V(n int) Verbose {
+	if !l.verbose.enabled(n) {
+		return Verbose{}
+	}
+
+	return Verbose{
+		logger: l,
+	}
+}
+
+// SetVerbosity sets verbosity of V(n), a negative level disables all of them.
+// NOTE: Verbosity is shared by all loggers created with l.New().
+func (l *Logger) SetVerbosity(level int) {
+	if l.verbose == nil {
+		return
+	}
+
+	l.verbose.mux.Lock()
+	l.verbose.level.Store(int32(level))
+	l.verbose.update()
+	l.verbose.mux.Unlock()
+}
+
+// Verbosity returns verbosity of V(n).
+func (l *Logger) Verbosity() int {
+	if l.verbose == nil {
+		return 0
+	}
+
+	return int(l.verbose.level.Load())
+}
+
+// SetVModule sets verbosity by file patterns, e.g. cache*=3,pool.go=4.
+// Patterns are matched against base name of files without the .go suffix, or
+// trailing segments of paths if they contain slashes, e.g. pkg/db/*=2.
+// The first pattern matching wins, an empty spec removes all patterns.
+// NOTE: It's shared by all loggers created with l.New().
+func (l *Logger) SetVModule(spec string) error {
+	modules, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+
+	if l.verbose == nil {
+		return nil
+	}
+
+	l.verbose.mux.Lock()
+	l.verbose.modules.Store(modules)
+	l.verbose.update()
+	l.verbose.mux.Unlock()
+
+	return nil
+}
+
+// VModule returns the vmodule setting.
+func (l *Logger) VModule() string {
+	if l.verbose == nil {
+		return ""
+	}
+
+	modules := l.verbose.modules.Load()
+	if modules == nil {
+		return ""
+	}
+
+	return modules.spec
+}
+
+// update recomputes max level, it must be called with lock held.
+func (v *verbosity) update() {
+	max := v.level.Load()
+	if modules := v.modules.Load(); modules != nil {
+		for _, p := range modules.patterns {
+			if p.level > max {
+				max = p.level
+			}
+		}
+	}
+
+	v.max.Store(max)
+}
+
+// enabled returns whether V(n) of the caller is enabled.
+// NOTE: It must be called by V methods directly for resolving call site.
+func (v *verbosity) enabled(n int) bool {
+	if v == nil || int32(n) > v.max.Load() {
+		return false
+	}
+
+	modules := v.modules.Load()
+	if modules == nil {
+		return int32(n) <= v.level.Load()
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(3, pcs[:]) < 1 {
+		return int32(n) <= v.level.Load()
+	}
+
+	if level, ok := modules.sites.Load(pcs[0]); ok {
+		return int32(n) <= level.(int32)
+	}
+
+	level := v.level.Load()
+	if matched, ok := modules.match(resolveCallsite(pcs[0]).file); ok {
+		level = matched
+	}
+
+	modules.sites.Store(pcs[0], level)
+
+	return int32(n) <= level
+}
+
+// match returns level of the first pattern matching file.
+func (vm *vmodule) match(file string) (int32, bool) {
+	file = strings.TrimSuffix(file, ".go")
+	base := path.Base(file)
+
+	for _, p := range vm.patterns {
+		name := base
+		if p.slashes > 0 {
+			name = lastSegments(file, p.slashes)
+		}
+
+		if ok, _ := path.Match(p.pattern, name); ok {
+			return p.level, true
+		}
+	}
+
+	return 0, false
+}
+
+// lastSegments returns the last n+1 segments of file.
+func lastSegments(file string, n int) string {
+	i := len(file)
+	for ; n >= 0 && i > 0; n-- {
+		i = strings.LastIndexByte(file[:i], '/')
+		if i < 0 {
+			return file
+		}
+	}
+
+	return file[i+1:]
+}
+
+func parseVModule(spec string) (*vmodule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	modules := &vmodule{
+		spec: spec,
+	}
+	for _, item := range strings.Split(spec, ",") {
+		pattern, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("%w: %q", ErrVModule, item)
+		}
+
+		level, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || level < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrVModule, item)
+		}
+
+		pattern = strings.TrimSuffix(pattern, ".go")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrVModule, item)
+		}
+
+		modules.patterns = append(modules.patterns, modulePattern{
+			pattern: pattern,
+			slashes: strings.Count(pattern, "/"),
+			level:   int32(level),
+		})
+	}
+
+	return modules, nil
+}
+
+func (log *structLog) V(n int) StructLogger {
+	if !log.logger.verbose.enabled(n) {
+		return nopLog{}
+	}
+
+	return log
+}
+
+// Verbose is returned by l.V(n), it logs with the logger if V(n) is enabled,
+// and discards all logs otherwise.
+// NOTE: Its methods call methods of Logger at the same depth for resolving caller.
+type Verbose struct {
+	logger *Logger
+}
+
+var _ GatedLogger = Verbose{}
+
+// Enabled returns whether V(n) is enabled.
+func (v Verbose) Enabled() bool {
+	return v.logger != nil
+}
+
+// Print is the same as l.Print if V(n) is enabled.
+func (v Verbose) Print(args ...any) {
+	if v.logger == nil {
+		return
+	}
+
+	v.logger.Output(Llog, fmt.Sprint(args...))
+}
+
+// Printf is the same as l.Printf if V(n) is enabled.
+func (v Verbose) Printf(format string, args ...any) {
+	if v.logger == nil {
+		return
+	}
+
+	v.logger.Output(Llog, fmt.Sprintf(format, args...))
+}
+
+// Debug is the same as l.Debug if V(n) is enabled.
+func (v Verbose) Debug(args ...any) {
+	if v.logger == nil || !v.logger.accept(Ldebug) {
+		return
+	}
+
+	v.logger.log(Ldebug, fmt.Sprint(args...))
+}
+
+// Debugf is the same as l.Debugf if V(n) is enabled.
+func (v Verbose) Debugf(format string, args ...any) {
+	if v.logger == nil || !v.logger.accept(Ldebug) {
+		return
+	}
+
+	v.logger.logf(Ldebug, format, args...)
+}
+
+// Info is the same as l.Info if V(n) is enabled.
+func (v Verbose) Info(args ...any) {
+	if v.logger == nil || !v.logger.accept(Linfo) {
+		return
+	}
+
+	v.logger.log(Linfo, fmt.Sprint(args...))
+}
+
+// Infof is the same as l.Infof if V(n) is enabled.
+func (v Verbose) Infof(format string, args ...any) {
+	if v.logger == nil || !v.logger.accept(Linfo) {
+		return
+	}
+
+	v.logger.logf(Linfo, format, args...)
+}
+
+// Warn is the same as l.Warn if V(n) is enabled.
+func (v Verbose) Warn(args ...any) {
+	if v.logger == nil || !v.logger.accept(Lwarn) {
+		return
+	}
+
+	v.logger.log(Lwarn, fmt.Sprint(args...))
+}
+
+// Warnf is the same as l.Warnf if V(n) is enabled.
+func (v Verbose) Warnf(format string, args ...any) {
+	if v.logger == nil || !v.logger.accept(Lwarn) {
+		return
+	}
+
+	v.logger.logf(Lwarn, format, args...)
+}
+
+// Error is the same as l.Error if V(n) is enabled.
+func (v Verbose) Error(args ...any) {
+	if v.logger == nil || !v.logger.accept(Lerror) {
+		return
+	}
+
+	v.logger.log(Lerror, fmt.Sprint(args...))
+}
+
+// Errorf is the same as l.Errorf if V(n) is enabled.
+func (v Verbose) Errorf(format string, args ...any) {
+	if v.logger == nil || !v.logger.accept(Lerror) {
+		return
+	}
+
+	v.logger.logf(Lerror, format, args...)
+}
+
+// Fatal is the same as l.Fatal if V(n) is enabled.
+func (v Verbose) Fatal(args ...any) {
+	if v.logger == nil || v.logger.Level() > Lfatal {
+		return
+	}
+
+	s := fmt.Sprint(args...)
+	v.logger.Output(Lfatal, s)
+	v.logger.crashed(s, nil)
+	os.Exit(1)
+}
+
+// Fatalf is the same as l.Fatalf if V(n) is enabled.
+func (v Verbose) Fatalf(format string, args ...any) {
+	if v.logger == nil || v.logger.Level() > Lfatal {
+		return
+	}
+
+	s := fmt.Sprintf(format, args...)
+	v.logger.Output(Lfatal, s)
+	v.logger.crashed(s, nil)
+	os.Exit(1)
+}
+
+// Panic is the same as l.Panic if V(n) is enabled.
+func (v Verbose) Panic(args ...any) {
+	if v.logger == nil || v.logger.Level() > Lpanic {
+		return
+	}
+
+	s := fmt.Sprint(args...)
+	v.logger.Output(Lpanic, s)
+	panic(s)
+}
+
+// Panicf is the same as l.Panicf if V(n) is enabled.
+func (v Verbose) Panicf(format string, args ...any) {
+	if v.logger == nil || v.logger.Level() > Lpanic {
+		return
+	}
+
+	s := fmt.Sprintf(format, args...)
+	v.logger.Output(Lpanic, s)
+	panic(s)
+}
@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_V(t *testing.T) {
+	assertion := assert.New(t)
+
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+
+	// V(0) is enabled by default
+	logger.V(0).Info("v0")
+	logger.V(1).Info("v1")
+	assertion.Contains(buf.String(), "v0")
+	assertion.NotContains(buf.String(), "v1")
+
+	logger.SetVerbosity(2)
+	assertion.Equal(2, logger.Verbosity())
+
+	child := logger.New("child")
+	for i := 0; i < 4; i++ {
+		child.V(i).Debugf("verbose #%d", i)
+	}
+	assertion.Equal(3, strings.Count(buf.String(), "verbose #"))
+	assertion.NotContains(buf.String(), "verbose #3")
+
+	buf.Reset()
+	logger.NewTextLogger().V(2).Str("key", "value").Info("struct")
+	logger.NewTextLogger().V(3).Info("hidden")
+	assertion.Contains(buf.String(), "key=value, msg=struct")
+	assertion.NotContains(buf.String(), "hidden")
+
+	// disable all
+	buf.Reset()
+	logger.SetVerbosity(-1)
+	logger.V(0).Info("v0")
+	assertion.Empty(buf.String())
+}
+
+func Test_Logger_SetVModule(t *testing.T) {
+	assertion := assert.New(t)
+
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetVerbosity(1)
+
+	assertion.Nil(logger.SetVModule("cache*=3, verbose_test.go=4"))
+	assertion.Equal("cache*=3, verbose_test.go=4", logger.VModule())
+
+	// cached by call sites
+	for i := 0; i < 6; i++ {
+		logger.V(i).Infof("module #%d", i)
+	}
+	assertion.Equal(5, strings.Count(buf.String(), "module #"))
+	assertion.NotContains(buf.String(), "module #5")
+
+	// the first pattern matching wins, trailing segments of paths match patterns
+	// with slash, e.g. module/verbose_test
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Base(filepath.Dir(file))
+
+	assertion.Nil(logger.SetVModule(dir + "/verbose_test=2,verbose*=5"))
+
+	buf.Reset()
+	for i := 0; i < 6; i++ {
+		logger.V(i).Infof("module #%d", i)
+	}
+	assertion.Equal(3, strings.Count(buf.String(), "module #"))
+
+	// others fall back to verbosity
+	assertion.Nil(logger.SetVModule("cache*=3"))
+
+	buf.Reset()
+	for i := 0; i < 6; i++ {
+		logger.V(i).Infof("module #%d", i)
+	}
+	assertion.Equal(2, strings.Count(buf.String(), "module #"))
+
+	// remove
+	assertion.Nil(logger.SetVModule(""))
+	assertion.Empty(logger.VModule())
+
+	for _, spec := range []string{"cache", "cache=", "=3", "cache=-1", "cache=x", "[=1"} {
+		err := logger.SetVModule(spec)
+		assertion.True(errors.Is(err, ErrVModule), spec)
+	}
+}
+
+func Test_Logger_VModuleMatch(t *testing.T) {
+	assertion := assert.New(t)
+
+	modules, err := parseVModule("cache*=3,pool.go=4,pkg/db/*=5")
+	assertion.Nil(err)
+
+	for file, expected := range map[string]int32{
+		"/src/cache.go":        3,
+		"/src/cache_lru.go":    3,
+		"/src/pool.go":         4,
+		"/src/pkg/db/conn.go":  5,
+		"/src/pkg/db/cache.go": 3,
+	} {
+		level, ok := modules.match(file)
+		assertion.True(ok, file)
+		assertion.Equal(expected, level, file)
+	}
+
+	_, ok := modules.match("/src/pool_test.go")
+	assertion.False(ok)
+}
+
+func Test_Logger_VWithoutAllocs(t *testing.T) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+	logger.SetVModule("verbose_test=2")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.V(3).Infof("disabled %d", 3)
+		logger.V(2).Enabled()
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func Test_Logger_VCaller(t *testing.T) {
+	assertion := assert.New(t)
+
+	var buf bytes.Buffer
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&buf)
+	logger.SetFlag(log.Lshortfile)
+	logger.SetSkip(3)
+
+	// logs are reported at the call site like ones of Logger
+	_, _, line, _ := runtime.Caller(0)
+	logger.V(0).Infof("v%d", 0)
+	logger.Info("logger")
+	assertion.Contains(buf.String(), "verbose_test.go:"+strconv.Itoa(line+1)+": v0")
+	assertion.Contains(buf.String(), "verbose_test.go:"+strconv.Itoa(line+2)+": logger")
+
+	// disabled V(n) discards all logs
+	buf.Reset()
+	v := logger.V(9)
+	v.Info("hidden")
+	v.Printf("hidden %d", 9)
+	v.Panic("hidden")
+	v.Fatal("hidden")
+	assertion.False(v.Enabled())
+	assertion.True(logger.V(0).Enabled())
+	assertion.Empty(buf.String())
+}
+
+func Test_Logger_VConcurrent(t *testing.T) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				logger.SetVerbosity(j % 4)
+				logger.SetVModule("verbose*=3")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				logger.V(j % 5).Info("race")
+				logger.NewJsonLogger().V(j % 5).Info("race")
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func Benchmark_Logger_VDisabled(b *testing.B) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+	logger.SetVModule("cache*=3")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.V(4).Infof("disabled %d", i)
+	}
+}
+
+func Benchmark_Logger_VModule(b *testing.B) {
+	logger, _ := New("stdout")
+	logger.SetOutput(io.Discard)
+	logger.SetVModule("verbose_test=3")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.V(2).Enabled()
+	}
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"io"
+	"sync"
+)
+
+// sharedWriter serializes writes of a logger tree. Each write is a whole
+// formatted record, so records of a logger and loggers created with l.New()
+// never interleave, even if they're writing to the same output concurrently.
+type sharedWriter struct {
+	mux sync.Mutex
+}
+
+func newSharedWriter() *sharedWriter {
+	return &sharedWriter{}
+}
+
+// write writes b to w exclusively.
+func (sw *sharedWriter) write(w io.Writer, b []byte) (int, error) {
+	sw.mux.Lock()
+	defer sw.mux.Unlock()
+
+	return w.Write(b)
+}
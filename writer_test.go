@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_Logger_SharedWriter(t *testing.T) {
+	var w lineWriter
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&w)
+	logger.SetColor(false)
+	logger.SetFlag(0)
+
+	var (
+		wg sync.WaitGroup
+
+		children = 20
+		logs     = 50
+	)
+	wg.Add(children + 1)
+
+	go func() {
+		defer wg.Done()
+
+		for n := 0; n < logs; n++ {
+			logger.Infof("parent %d", n)
+		}
+	}()
+
+	for i := 0; i < children; i++ {
+		go func(child int) {
+			defer wg.Done()
+
+			log := logger.New(fmt.Sprintf("child-%d", child))
+			if child%2 == 0 {
+				// grandchildren share the writer too
+				log = log.New(fmt.Sprintf("child-%d", child), "grandchild")
+			}
+
+			for n := 0; n < logs; n++ {
+				switch n % 3 {
+				case 0:
+					log.Warnf("%s %d", strings.Repeat("x", 256), n)
+				case 1:
+					log.NewJsonLogger().Int("n", n).Str("payload", strings.Repeat("y", 128)).Info("json")
+				default:
+					log.LogAttrs(context.Background(), slog.LevelError, "attrs", slog.Int("n", n))
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.False(t, w.overlap.Load())
+	assert.Equal(t, (children+1)*logs, len(w.lines))
+
+	for _, line := range w.lines {
+		assert.True(t, strings.HasPrefix(line, "["))
+		assert.Equal(t, 1, strings.Count(line, "\n"))
+	}
+}
+
+func Test_Logger_SharedWriterAfterSetOutput(t *testing.T) {
+	var w lineWriter
+
+	logger, _ := New("stdout")
+	logger.SetOutput(&w)
+
+	child := logger.New("child")
+	child.SetOutput(&w)
+
+	assert.Equal(t, logger.writer, child.writer)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	for _, log := range []*Logger{logger, child} {
+		go func(log *Logger) {
+			defer wg.Done()
+
+			for n := 0; n < 100; n++ {
+				log.Info("line")
+			}
+		}(log)
+	}
+
+	wg.Wait()
+
+	assert.False(t, w.overlap.Load())
+	assert.Equal(t, 200, len(w.lines))
+}